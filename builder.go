@@ -0,0 +1,207 @@
+package jsong
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Builder builds jsong values with chained calls.
+//
+//	v, err := jsong.NewBuilder().Obj().
+//		Set("name", "jsong").
+//		Set("meta.version", 2).
+//		Key("tags").Arr().Append("json").Append("go").End().
+//		End().Build()
+//
+// Obj and Arr open a container at the top level, as the element of an
+// array or as the value of the key given by Key in an object. End closes
+// the innermost container. Go values are converted with ValueOf.
+//
+// The first error is kept and returned by Build.
+// Calls after an error have no effect.
+type Builder struct {
+	opts   ValueOptions
+	frames []builderFrame // Open containers from the root.
+	key    *string        // Pending key given by Key.
+	root   valueInterface
+	err    error
+}
+
+type builderFrame struct {
+	v   valueInterface
+	key string // Key in the parent object.
+}
+
+// NewBuilder returns a new Builder.
+func NewBuilder() *Builder {
+	return ValueOptions{}.NewBuilder()
+}
+
+// NewBuilder returns a new Builder which converts Go values with the options o.
+// Objects keep their keys in insertion order with OrderedObjects.
+func (o ValueOptions) NewBuilder() *Builder {
+	return &Builder{opts: o}
+}
+
+func (b *Builder) fail(format string, args ...any) *Builder {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, args...)
+	}
+	return b
+}
+
+// top returns the innermost open container or nil.
+func (b *Builder) top() valueInterface {
+	if len(b.frames) == 0 {
+		return nil
+	}
+	return b.frames[len(b.frames)-1].v
+}
+
+// Obj opens an object.
+func (b *Builder) Obj() *Builder {
+	if b.opts.OrderedObjects {
+		return b.open("Obj", newOrderedObject(0))
+	}
+	return b.open("Obj", make(object))
+}
+
+// Arr opens an array.
+func (b *Builder) Arr() *Builder {
+	return b.open("Arr", array{})
+}
+
+func (b *Builder) open(method string, v valueInterface) *Builder {
+	if b.err != nil {
+		return b
+	}
+	var key string
+	switch top := b.top(); {
+	case top == nil:
+		if b.root != nil {
+			return b.fail("Builder.%s: value already built", method)
+		}
+	case isObject(top):
+		if b.key == nil {
+			return b.fail("Builder.%s: missing Key in object", method)
+		}
+		key, b.key = *b.key, nil
+	}
+	b.frames = append(b.frames, builderFrame{v: v, key: key})
+	return b
+}
+
+// Key sets the key in the open object for the next Obj or Arr.
+func (b *Builder) Key(k string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if !isObject(b.top()) {
+		return b.fail("Builder.Key: not in an object")
+	}
+	if b.key != nil {
+		return b.fail("Builder.Key: missing value for key %q", *b.key)
+	}
+	b.key = &k
+	return b
+}
+
+// Set sets the path in the open object to the value of x like the
+// function Set. Intermediate objects and arrays are created as needed.
+func (b *Builder) Set(path string, x any) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if !isObject(b.top()) {
+		return b.fail("Builder.Set: not in an object")
+	}
+	if b.key != nil {
+		return b.fail("Builder.Set: missing value for key %q", *b.key)
+	}
+	v, err := b.valueOf(x)
+	if err != nil {
+		return b.fail("Builder.Set: %w", err)
+	}
+	p, ok := parsePath(path)
+	if !ok {
+		return b.fail("Builder.Set: invalid path %q", path)
+	}
+	if len(p) == 0 {
+		return b.fail("Builder.Set: empty path")
+	}
+	if _, err := setAt(b.top(), p, v, false); err != nil {
+		return b.fail("Builder.%w", err)
+	}
+	return b
+}
+
+// Append appends the value of x to the open array.
+func (b *Builder) Append(x any) *Builder {
+	if b.err != nil {
+		return b
+	}
+	top := len(b.frames) - 1
+	if top < 0 {
+		return b.fail("Builder.Append: not in an array")
+	}
+	a, ok := b.frames[top].v.(array)
+	if !ok {
+		return b.fail("Builder.Append: not in an array")
+	}
+	v, err := b.valueOf(x)
+	if err != nil {
+		return b.fail("Builder.Append: %w", err)
+	}
+	b.frames[top].v = append(a, v)
+	return b
+}
+
+// End closes the innermost open container.
+func (b *Builder) End() *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.frames) == 0 {
+		return b.fail("Builder.End: no open container")
+	}
+	if b.key != nil {
+		return b.fail("Builder.End: missing value for key %q", *b.key)
+	}
+	f := b.frames[len(b.frames)-1]
+	b.frames = b.frames[:len(b.frames)-1]
+	switch top := b.top(); {
+	case top == nil:
+		b.root = f.v
+	case isObject(top):
+		top.Put(f.key, f.v)
+	default:
+		b.frames[len(b.frames)-1].v = append(top.(array), f.v)
+	}
+	return b
+}
+
+// Build returns the built value or the first error.
+// All containers must be closed.
+func (b *Builder) Build() (any, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.frames) > 0 {
+		return nil, fmt.Errorf("Builder.Build: %d unclosed containers", len(b.frames))
+	}
+	if b.root == nil {
+		return nil, errors.New("Builder.Build: no value")
+	}
+	return b.root, nil
+}
+
+func (b *Builder) valueOf(x any) (valueInterface, error) {
+	v, err := b.opts.ValueOfErr(x)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := v.(valueInterface); ok {
+		return v, nil
+	}
+	return null{}, nil // Nil pointers.
+}
@@ -0,0 +1,106 @@
+package jsong
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuilder(t *testing.T) {
+	got, err := NewBuilder().Obj().
+		Set("name", "jsong").
+		Set("meta.version", 2).
+		Set("meta.stable", false).
+		Set("list.1", true).
+		Set("list.-", "last").
+		Key("tags").Arr().Append("json").Append([]int{1}).Obj().Set("x", nil).End().Arr().End().End().
+		Key("empty").Obj().End().
+		End().Build()
+	if err != nil {
+		t.Fatalf("Build(): got err = %v, want err = false", err)
+	}
+
+	want := object{
+		"name":  str("jsong"),
+		"meta":  object{"version": num(2), "stable": boolean(false)},
+		"list":  array{null{}, boolean(true), str("last")},
+		"tags":  array{str("json"), array{num(1)}, object{"x": null{}}, array{}},
+		"empty": object{},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Build(): got diff:\n%s", diff)
+	}
+}
+
+func TestBuilderOrdered(t *testing.T) {
+	v, err := ValueOptions{OrderedObjects: true, ExactNumbers: true}.NewBuilder().Obj().
+		Set("b", 1).
+		Key("a").Arr().Append(2).End().
+		Set("c.z", 3).
+		Set("c.y", 4).
+		End().Build()
+	if err != nil {
+		t.Fatalf("Build(): got err = %v, want err = false", err)
+	}
+
+	if got, want := encodeString(t, EncoderOptions{}, v), `{"b":1,"a":[2],"c":{"z":3,"y":4}}`+"\n"; got != want {
+		t.Errorf("Build(): got %s, want %s", got, want)
+	}
+	if _, ok := Extract(v, "b").(decimal); !ok {
+		t.Errorf("Build(): got %T, want exact number", Extract(v, "b"))
+	}
+}
+
+func TestBuilderArray(t *testing.T) {
+	got, err := NewBuilder().Arr().Append(1).Obj().Set("a", "b").End().End().Build()
+	if err != nil {
+		t.Fatalf("Build(): got err = %v, want err = false", err)
+	}
+
+	if diff := cmp.Diff(array{num(1), object{"a": str("b")}}, got); diff != "" {
+		t.Errorf("Build(): got diff:\n%s", diff)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		b    *Builder
+	}{
+		{name: "empty", b: NewBuilder()},
+		{name: "unclosed", b: NewBuilder().Obj()},
+		{name: "extra End", b: NewBuilder().Obj().End().End()},
+		{name: "second root", b: NewBuilder().Obj().End().Arr().End()},
+		{name: "Obj without Key", b: NewBuilder().Obj().Obj().End().End()},
+		{name: "Key in array", b: NewBuilder().Arr().Key("a").End()},
+		{name: "Key without value", b: NewBuilder().Obj().Key("a").End()},
+		{name: "Set in array", b: NewBuilder().Arr().Set("a", 1).End()},
+		{name: "Set through scalar", b: NewBuilder().Obj().Set("a", 1).Set("a.b", 2).End()},
+		{name: "Set empty path", b: NewBuilder().Obj().Set("", 1).End()},
+		{name: "Append in object", b: NewBuilder().Obj().Append(1).End()},
+		{name: "Append at top level", b: NewBuilder().Append(1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := tc.b.Build(); err == nil {
+				t.Errorf("Build(): got %v, want err = true", got)
+			}
+		})
+	}
+}
+
+func TestBuilderUnsupported(t *testing.T) {
+	b := NewBuilder().Obj().Set("f", func() {}).Set("a", 1).End()
+
+	if _, err := b.Build(); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Build(): got err = %v, want ErrUnsupportedType", err)
+	}
+
+	got, err := ValueOptions{Unsupported: UnsupportedNull}.NewBuilder().Obj().Set("f", func() {}).End().Build()
+	if err != nil {
+		t.Fatalf("Build(): got err = %v, want err = false", err)
+	}
+	if diff := cmp.Diff(object{"f": null{}}, got); diff != "" {
+		t.Errorf("Build(): got diff:\n%s", diff)
+	}
+}
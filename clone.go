@@ -0,0 +1,84 @@
+package jsong
+
+import (
+	"slices"
+
+	"golang.org/x/exp/maps"
+)
+
+// Clone returns a deep copy of the jsong value v which shares
+// no objects or arrays with v. Go values are converted with ValueOf
+// which always returns new values. Persistent values are immutable
+// and returned as is. See Thaw. Values which ValueOf cannot convert
+// return nil.
+func Clone(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case valueInterface:
+		return cloneValue(v, false)
+	default:
+		return valueOf(v)
+	}
+}
+
+// cloneValue returns a deep copy of v. With thaw, persistent values
+// are copied to objects and arrays instead of returned as is.
+func cloneValue(v valueInterface, thaw bool) valueInterface {
+	if thaw {
+		v = materialize(v)
+	}
+	switch v := v.(type) {
+	case array:
+		if v == nil {
+			return array(nil)
+		}
+		res := make(array, len(v))
+		for i, e := range v {
+			if e != nil {
+				res[i] = cloneValue(e.(valueInterface), thaw)
+			}
+		}
+		return res
+	case object:
+		if v == nil {
+			return object(nil)
+		}
+		res := make(object, len(v))
+		for k, e := range v {
+			if e != nil {
+				e = cloneValue(e.(valueInterface), thaw)
+			}
+			res[k] = e
+		}
+		return res
+	case *orderedObject:
+		res := newOrderedObject(len(v.keys))
+		for _, k := range v.keys {
+			e := v.m[k]
+			if e != nil {
+				e = cloneValue(e.(valueInterface), thaw)
+			}
+			res.set(k, e)
+		}
+		return res
+	default:
+		// Scalars are immutable.
+		return v
+	}
+}
+
+// shallowClone returns a copy of the object or array v
+// which shares its elements with v.
+func shallowClone(v valueInterface) valueInterface {
+	switch v := v.(type) {
+	case array:
+		return slices.Clone(v)
+	case object:
+		return maps.Clone(v)
+	case *orderedObject:
+		return &orderedObject{keys: slices.Clone(v.keys), m: maps.Clone(v.m)}
+	default:
+		return v
+	}
+}
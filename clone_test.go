@@ -0,0 +1,161 @@
+package jsong
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newCloneInput() any {
+	return object{
+		"a": array{num(3), nil, object{"b": str("c")}},
+		"d": object{"e": array(nil), "f": object(nil)},
+		"g": boolean(true),
+		"h": array{num(2), num(1)},
+	}
+}
+
+func TestClone(t *testing.T) {
+	v := newCloneInput()
+
+	got := Clone(v)
+
+	if diff := cmp.Diff(v, got); diff != "" {
+		t.Fatalf("Clone(): got diff:\n%s", diff)
+	}
+	got.(object)["a"].(array)[2].(object)["b"] = str("x")
+	got.(object)["d"].(object)["e"] = null{}
+	got.(object)["h"].(array)[0] = null{}
+	if diff := cmp.Diff(newCloneInput(), v); diff != "" {
+		t.Errorf("Clone(): modifying the clone modified the input:\n%s", diff)
+	}
+}
+
+func TestCloneOrdered(t *testing.T) {
+	v := decodeOrdered(t, `{"b":{"y":1,"x":2},"a":[{"z":3}]}`)
+
+	got := Clone(v)
+	Must(Set(got, "b.w", 4))
+	Must(Set(got, "a.0.z", 5))
+
+	if got, want := encodeString(t, EncoderOptions{}, v), `{"b":{"y":1,"x":2},"a":[{"z":3}]}`+"\n"; got != want {
+		t.Errorf("Clone(): got input %s, want %s", got, want)
+	}
+	if got, want := encodeString(t, EncoderOptions{}, got), `{"b":{"y":1,"x":2,"w":4},"a":[{"z":5}]}`+"\n"; got != want {
+		t.Errorf("Clone(): got %s, want %s", got, want)
+	}
+}
+
+func TestCloneGoValue(t *testing.T) {
+	if diff := cmp.Diff(object{"a": num(1)}, Clone(map[string]int{"a": 1})); diff != "" {
+		t.Errorf("Clone(): got diff:\n%s", diff)
+	}
+	if got := Clone(nil); got != nil {
+		t.Errorf("Clone(nil): got %v, want nil", got)
+	}
+}
+
+// mutationTestCase tests the mutation contract of a function
+// and its variant which leaves its input unchanged.
+type mutationTestCase struct {
+	name     string
+	mutate   func(v any) any
+	copy     func(v any) any
+	want     any
+	mutating bool // Whether mutate modifies its jsong input.
+}
+
+func (tc mutationTestCase) runTest(t *testing.T) {
+	t.Helper()
+
+	// The variant leaves its input and the values it shares unchanged.
+	v := newCloneInput()
+	got := tc.copy(v)
+	if diff := cmp.Diff(newCloneInput(), v); diff != "" {
+		t.Errorf("%s(): copy variant modified the input:\n%s", tc.name, diff)
+	}
+	if diff := cmp.Diff(tc.want, got); diff != "" {
+		t.Errorf("%s(): copy variant got diff:\n%s", tc.name, diff)
+	}
+
+	// The function modifies jsong values in place.
+	v = newCloneInput()
+	got = tc.mutate(v)
+	if diff := cmp.Diff(tc.want, got); diff != "" {
+		t.Errorf("%s(): got diff:\n%s", tc.name, diff)
+	}
+	if modified := !cmp.Equal(newCloneInput(), v); modified != tc.mutating {
+		t.Errorf("%s(): got modified input = %v, want %v", tc.name, modified, tc.mutating)
+	}
+
+	// Go values are never modified.
+	newGoValue := func() map[string]any { return map[string]any{"a": []any{1, nil, map[string]any{"b": "c"}}, "g": true} }
+	goValue := newGoValue()
+	tc.mutate(goValue)
+	if diff := cmp.Diff(newGoValue(), goValue); diff != "" {
+		t.Errorf("%s(): modified the Go value:\n%s", tc.name, diff)
+	}
+}
+
+func TestMutationContracts(t *testing.T) {
+	want := func(fn func(v object)) any {
+		v := newCloneInput().(object)
+		fn(v)
+		return v
+	}
+	for _, tc := range []mutationTestCase{{
+		name:     "Sort",
+		mutate:   func(v any) any { return Sort(Extract(v, "h")) },
+		copy:     func(v any) any { return SortCopy(Extract(v, "h")) },
+		want:     array{num(1), num(2)},
+		mutating: true,
+	}, {
+		name:     "SortByKey",
+		mutate:   func(v any) any { return SortByKey(Extract(v, "h"), "") },
+		copy:     func(v any) any { return SortByKeyCopy(Extract(v, "h"), "") },
+		want:     array{num(1), num(2)},
+		mutating: true,
+	}, {
+		name:     "Delete",
+		mutate:   func(v any) any { return Delete(v, "a.2.b") },
+		copy:     func(v any) any { return DeleteCopy(v, "a.2.b") },
+		want:     want(func(v object) { delete(v["a"].(array)[2].(object), "b") }),
+		mutating: true,
+	}, {
+		name:     "Delete missing",
+		mutate:   func(v any) any { return Delete(v, "a.5.b") },
+		copy:     func(v any) any { return DeleteCopy(v, "a.5.b") },
+		want:     newCloneInput(),
+		mutating: false,
+	}, {
+		name:     "Merge",
+		mutate:   func(v any) any { return Merge(v, v, "d.e.1", "g") },
+		copy:     func(v any) any { return MergeCopy(v, v, "d.e.1", "g") },
+		want:     want(func(v object) { v["d"].(object)["e"] = array{null{}, boolean(true)} }),
+		mutating: true,
+	}, {
+		name:     "Set",
+		mutate:   func(v any) any { return Must(Set(v, "a.2.b", 1)) },
+		copy:     func(v any) any { return Must(SetCopy(v, "a.2.b", 1)) },
+		want:     want(func(v object) { v["a"].(array)[2].(object)["b"] = num(1) }),
+		mutating: true,
+	}, {
+		name:     "ObjectMapper",
+		mutate:   func(v any) any { return ObjectMapper{"g": AddScalar{}}.Map(v) },
+		copy:     func(v any) any { return CloneMapper{ObjectMapper{"g": AddScalar{}}}.Map(v) },
+		want:     newCloneInput(),
+		mutating: false,
+	}, {
+		name:   "ObjectMapper modified",
+		mutate: func(v any) any { return ObjectMapper{"a": ArrayMapper{MapSeq{}, MapSeq{}, ObjectRemapper{}}}.Map(v) },
+		copy: func(v any) any {
+			return CloneMapper{ObjectMapper{"a": ArrayMapper{MapSeq{}, MapSeq{}, ObjectRemapper{}}}}.Map(v)
+		},
+		want:     want(func(v object) { v["a"].(array)[2] = object{} }),
+		mutating: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
@@ -6,14 +6,11 @@ import (
 	"golang.org/x/exp/maps"
 )
 
+// Compare compares the values a and b and returns -1, 0 or +1.
+// Values which ValueOf cannot convert compare like nil, before
+// all other values.
 func Compare(a, b any) int {
-	if _, ok := a.(valueInterface); !ok {
-		a = ValueOf(a)
-	}
-	if _, ok := b.(valueInterface); !ok {
-		b = ValueOf(b)
-	}
-	return compare(a.(valueInterface), b.(valueInterface))
+	return compare(valueOf(a), valueOf(b))
 }
 
 func fastCompare(a, b any) (int, bool) {
@@ -48,38 +45,38 @@ func compare(a, b valueInterface) int {
 		default:
 			return -1
 		}
-	case num:
-		switch b := b.(type) {
+	case num, decimal:
+		switch b.(type) {
 		case null, boolean:
 			return +1
-		case num:
-			return a.compare(b)
+		case num, decimal:
+			return compareNumbers(a, b)
 		default:
 			return -1
 		}
 	case str:
 		switch b := b.(type) {
-		case null, boolean, num:
+		case null, boolean, num, decimal:
 			return +1
 		case str:
 			return a.compare(b)
 		default:
 			return -1
 		}
-	case array:
-		switch b := b.(type) {
-		case null, boolean, num, str:
+	case array, *persistentArray:
+		switch b.(type) {
+		case null, boolean, num, decimal, str:
 			return +1
-		case array:
+		case array, *persistentArray:
 			return a.compare(b)
 		default:
 			return -1
 		}
-	case object:
-		switch b := b.(type) {
-		case null, boolean, num, str, array:
+	case object, *orderedObject, *persistentObject:
+		switch b.(type) {
+		case null, boolean, num, decimal, str, array, *persistentArray:
 			return +1
-		case object:
+		case object, *orderedObject, *persistentObject:
 			return a.compare(b)
 		default:
 			return -1
@@ -127,7 +124,10 @@ func (a str) compare(other valueInterface) int {
 }
 
 func (a array) compare(other valueInterface) int {
-	b := other.(array)
+	b, ok := other.(array)
+	if !ok {
+		b = materialize(other).(array)
+	}
 	if a == nil {
 		if b == nil {
 			return 0
@@ -155,7 +155,15 @@ func (a array) compare(other valueInterface) int {
 }
 
 func (a object) compare(other valueInterface) int {
-	b := other.(object)
+	var b object
+	switch o := other.(type) {
+	case object:
+		b = o
+	case *orderedObject:
+		b = o.m
+	default:
+		b = materialize(other).(object)
+	}
 	if a == nil {
 		if b == nil {
 			return 0
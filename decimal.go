@@ -0,0 +1,211 @@
+package jsong
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// decimal is a number which keeps the exact text of its JSON literal.
+type decimal string
+
+func (decimal) Get(k any) (valueInterface, bool) { return nil, false }
+func (decimal) Put(k any, v valueInterface)      {}
+func (decimal) Delete(k any)                     {}
+func (decimal) Each(func(any, any) bool)         {}
+
+func (a decimal) compare(other valueInterface) int {
+	return compareNumbers(a, other)
+}
+
+// MarshalJSON writes the literal so encoding/json does not quote it.
+func (a decimal) MarshalJSON() ([]byte, error) { return []byte(a), nil }
+
+// Float64 returns the nearest float64 to the decimal.
+// Numbers outside the float64 range saturate to ±Inf.
+func (a decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(string(a), 64)
+	return f
+}
+
+// numberLike returns the result f of arithmetic on the numbers
+// operands as a decimal if any of them is a decimal and as a num
+// otherwise. Results which are not finite are always nums.
+func numberLike(f float64, operands ...any) valueInterface {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return num(f)
+	}
+	for _, x := range operands {
+		if _, ok := x.(decimal); ok {
+			return decimal(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+	return num(f)
+}
+
+// numberText returns the JSON text of a number as written by the Encoder.
+func numberText(v valueInterface) (string, bool) {
+	switch v := v.(type) {
+	case decimal:
+		return string(v), true
+	case num:
+		b, err := new(Encoder).appendNum(nil, float64(v))
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	default:
+		return "", false
+	}
+}
+
+// compareNumbers compares the num or decimal values a and b
+// by their exact decimal values.
+func compareNumbers(a, b valueInterface) int {
+	if a, ok := a.(num); ok {
+		if b, ok := b.(num); ok {
+			return a.compare(b)
+		}
+	}
+	if a, ok := a.(decimal); ok {
+		if b, ok := b.(decimal); ok && a == b {
+			return 0
+		}
+	}
+	return compareDecimals(numberParts(a), numberParts(b))
+}
+
+// numberParts returns the decimal parts of a num or decimal.
+func numberParts(v valueInterface) decimalParts {
+	if f, ok := v.(num); ok {
+		if math.IsInf(float64(f), 0) {
+			// Infinities sort beyond any finite exponent.
+			return decimalParts{neg: f < 0, digits: "1", exp: math.MaxInt64}
+		}
+		return parseDecimal(strconv.FormatFloat(float64(f), 'e', -1, 64))
+	}
+	return parseDecimal(string(v.(decimal)))
+}
+
+// decimalParts is a number in the normalized form ±0.digits × 10^exp.
+// The digits have no leading or trailing zeros and are empty for zero.
+type decimalParts struct {
+	neg    bool
+	digits string
+	exp    int64
+}
+
+// parseDecimal parses the normalized parts of a JSON number literal.
+func parseDecimal(s string) decimalParts {
+	var d decimalParts
+	if strings.HasPrefix(s, "-") {
+		d.neg = true
+		s = s[1:]
+	}
+	mant, expText, _ := strings.Cut(strings.ToLower(s), "e")
+	intPart, fracPart, _ := strings.Cut(mant, ".")
+	digits := intPart + fracPart
+	d.exp = int64(len(intPart))
+	if expText != "" {
+		e, err := strconv.ParseInt(expText, 10, 64)
+		if err != nil {
+			// Clamp exponents out of range.
+			e = math.MaxInt32
+			if strings.HasPrefix(expText, "-") {
+				e = math.MinInt32
+			}
+		}
+		d.exp += e
+	}
+	trimmed := strings.TrimLeft(digits, "0")
+	d.exp -= int64(len(digits) - len(trimmed))
+	d.digits = strings.TrimRight(trimmed, "0")
+	if d.digits == "" {
+		d.neg = false
+		d.exp = 0
+	}
+	return d
+}
+
+func compareDecimals(a, b decimalParts) int {
+	if a.neg != b.neg {
+		if a.neg {
+			return -1
+		}
+		return +1
+	}
+	sign := +1
+	if a.neg {
+		sign = -1
+	}
+	switch {
+	case a.digits == "" && b.digits == "":
+		return 0
+	case a.digits == "":
+		return -sign
+	case b.digits == "":
+		return +sign
+	case a.exp != b.exp:
+		if a.exp < b.exp {
+			return -sign
+		}
+		return +sign
+	default:
+		return sign * strings.Compare(a.digits, b.digits)
+	}
+}
+
+// int64Value returns the number v as an int64 if it is an integer in range.
+func int64Value(v valueInterface) (int64, bool) {
+	switch v := v.(type) {
+	case num:
+		if f := float64(v); f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), true
+		}
+	case decimal:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i, true
+		}
+		if i, ok := bigInt(v); ok && i.IsInt64() {
+			return i.Int64(), true
+		}
+	}
+	return 0, false
+}
+
+// uint64Value returns the number v as an uint64 if it is an integer in range.
+func uint64Value(v valueInterface) (uint64, bool) {
+	switch v := v.(type) {
+	case num:
+		if f := float64(v); f == math.Trunc(f) && f >= 0 && f < math.MaxUint64 {
+			return uint64(f), true
+		}
+	case decimal:
+		if i, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return i, true
+		}
+		if i, ok := bigInt(v); ok && i.IsUint64() {
+			return i.Uint64(), true
+		}
+	}
+	return 0, false
+}
+
+// bigInt returns the decimal as a big.Int if it is an integer
+// with a reasonable number of digits.
+func bigInt(v decimal) (*big.Int, bool) {
+	d := parseDecimal(string(v))
+	if d.digits == "" {
+		// Zero has no digits, whatever its fraction or exponent.
+		return new(big.Int), true
+	}
+	if d.exp < int64(len(d.digits)) || d.exp > 1000 {
+		return nil, false
+	}
+	i, ok := new(big.Int).SetString(d.digits+strings.Repeat("0", int(d.exp)-len(d.digits)), 10)
+	if ok && d.neg {
+		i.Neg(i)
+	}
+	return i, ok
+}
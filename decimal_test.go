@@ -0,0 +1,182 @@
+package jsong
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeUseNumber(t *testing.T) {
+	input := `{"id":9007199254740993,"big":1e400,"pi":3.14159265358979323846264338327950288,"neg":-0.0}`
+
+	v, err := DecoderOptions{UseNumber: true}.NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("Decode(): got err = %v, want err = false", err)
+	}
+
+	var sb strings.Builder
+	if err := (EncoderOptions{SortKeys: true}).NewEncoder(&sb).Encode(v); err != nil {
+		t.Fatalf("Encode(): got err = %v, want err = false", err)
+	}
+
+	want := `{"big":1e400,"id":9007199254740993,"neg":-0.0,"pi":3.14159265358979323846264338327950288}` + "\n"
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+
+	if got, ok := Int64(Extract(v, "id")); !ok || got != 9007199254740993 {
+		t.Errorf("Int64(): got %v, %v, want 9007199254740993, true", got, ok)
+	}
+}
+
+func TestDecodeUseNumberLenient(t *testing.T) {
+	v, err := DecoderOptions{UseNumber: true}.NewDecoder(strings.NewReader(`01.`)).Decode()
+	if err != nil {
+		t.Fatalf("Decode(): got err = %v, want err = false", err)
+	}
+	if diff := cmp.Diff(num(1), v); diff != "" {
+		t.Errorf("Decode(): got diff:\n%s", diff)
+	}
+}
+
+func TestValueOfExactNumbers(t *testing.T) {
+	input := map[string]any{
+		"int":    int64(math.MaxInt64),
+		"uint":   uint64(math.MaxUint64),
+		"float":  1.5,
+		"number": json.Number("12345678901234567890.5"),
+	}
+
+	got := ValueOptions{ExactNumbers: true}.ValueOf(input)
+
+	want := object{
+		"int":    decimal("9223372036854775807"),
+		"uint":   decimal("18446744073709551615"),
+		"float":  num(1.5),
+		"number": decimal("12345678901234567890.5"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ValueOf(): got diff:\n%s", diff)
+	}
+}
+
+func TestValueOfNumber(t *testing.T) {
+	got := ValueOf([]any{json.Number("1.5"), json.Number("")})
+
+	if diff := cmp.Diff(array{num(1.5), num(0)}, got); diff != "" {
+		t.Errorf("ValueOf(): got diff:\n%s", diff)
+	}
+}
+
+func TestValueOfInvalidNumber(t *testing.T) {
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("ValueOf(): got panic = false, want panic = true")
+		}
+	}()
+
+	ValueOf(json.Number("0x10"))
+}
+
+func TestCompareNumbers(t *testing.T) {
+	for _, tc := range []struct {
+		a, b any
+		want int
+	}{
+		{decimal("1"), decimal("1.0"), 0},
+		{decimal("1"), num(1), 0},
+		{decimal("0.1"), num(0.1), 0},
+		{decimal("-0"), num(0), 0},
+		{decimal("100"), decimal("1e2"), 0},
+		{decimal("9007199254740993"), num(9007199254740992), +1},
+		{decimal("-9007199254740993"), num(-9007199254740992), -1},
+		{decimal("1e400"), num(math.MaxFloat64), +1},
+		{decimal("1e400"), num(math.Inf(+1)), -1},
+		{decimal("-1e400"), num(math.Inf(-1)), +1},
+		{decimal("0.0001"), decimal("0.001"), -1},
+		{decimal("-2"), decimal("-10"), +1},
+		{decimal("0"), decimal("-1e-400"), +1},
+		{decimal("1"), boolean(true), +1},
+		{decimal("1"), str("1"), -1},
+	} {
+		if got := Compare(tc.a, tc.b); got != tc.want {
+			t.Errorf("Compare(%v, %v): got %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := Compare(tc.b, tc.a); got != -tc.want {
+			t.Errorf("Compare(%v, %v): got %d, want %d", tc.b, tc.a, got, -tc.want)
+		}
+	}
+}
+
+func TestSortExactNumbers(t *testing.T) {
+	got := Sort(array{decimal("9007199254740993"), num(9007199254740992), decimal("1e-400"), num(0)})
+
+	want := array{num(0), decimal("1e-400"), num(9007199254740992), decimal("9007199254740993")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Sort(): got diff:\n%s", diff)
+	}
+}
+
+func TestMergeExactNumbers(t *testing.T) {
+	a := object{"a": decimal("1")}
+	b := object{"b": decimal("18446744073709551616")}
+
+	got := Merge(a, b, "b", "b")
+
+	want := object{"a": decimal("1"), "b": decimal("18446744073709551616")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Merge(): got diff:\n%s", diff)
+	}
+}
+
+func TestNumberAccessors(t *testing.T) {
+	for _, tc := range []struct {
+		v        any
+		wantInt  int64
+		intOk    bool
+		wantUint uint64
+		uintOk   bool
+		wantNum  string
+	}{
+		{num(42), 42, true, 42, true, "42"},
+		{num(-1.5), 0, false, 0, false, "-1.5"},
+		{num(1e21), 0, false, 0, false, "1e+21"},
+		{decimal("-42"), -42, true, 0, false, "-42"},
+		{decimal("4.2e1"), 42, true, 42, true, "4.2e1"},
+		{decimal("18446744073709551615"), 0, false, math.MaxUint64, true, "18446744073709551615"},
+		{decimal("1e400"), 0, false, 0, false, "1e400"},
+		{decimal("0.5"), 0, false, 0, false, "0.5"},
+		{decimal("0.0"), 0, true, 0, true, "0.0"},
+		{decimal("0e5"), 0, true, 0, true, "0e5"},
+		{decimal("-0.00e-3"), 0, true, 0, true, "-0.00e-3"},
+		{decimal("0e99999999999999999999"), 0, true, 0, true, "0e99999999999999999999"},
+		{str("1"), 0, false, 0, false, ""},
+	} {
+		if got, ok := Int64(tc.v); got != tc.wantInt || ok != tc.intOk {
+			t.Errorf("Int64(%v): got %v, %v, want %v, %v", tc.v, got, ok, tc.wantInt, tc.intOk)
+		}
+		if got, ok := Uint64(tc.v); got != tc.wantUint || ok != tc.uintOk {
+			t.Errorf("Uint64(%v): got %v, %v, want %v, %v", tc.v, got, ok, tc.wantUint, tc.uintOk)
+		}
+		if got, ok := Number(tc.v); got != tc.wantNum || ok != (tc.wantNum != "") {
+			t.Errorf("Number(%v): got %q, %v, want %q", tc.v, got, ok, tc.wantNum)
+		}
+	}
+
+	if got, ok := Float64(decimal("0.25")); got != 0.25 || !ok {
+		t.Errorf("Float64(): got %v, %v, want 0.25, true", got, ok)
+	}
+}
+
+func TestDecimalMarshalJSON(t *testing.T) {
+	got, err := json.Marshal(array{decimal("12345678901234567890")})
+	if err != nil {
+		t.Fatalf("json.Marshal(): got err: %v", err)
+	}
+	if diff := cmp.Diff(`[12345678901234567890]`, string(got)); diff != "" {
+		t.Errorf("json.Marshal(): got diff:\n%s", diff)
+	}
+}
@@ -5,55 +5,266 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"iter"
 	"strconv"
-	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
+// InvalidUTF8Policy controls how the Decoder handles strings
+// containing invalid UTF-8 or unpaired UTF-16 surrogate escapes.
+type InvalidUTF8Policy int
+
+const (
+	// InvalidUTF8Replace replaces each invalid byte and each unpaired
+	// surrogate with the Unicode replacement character U+FFFD.
+	InvalidUTF8Replace InvalidUTF8Policy = iota
+	// InvalidUTF8Error fails decoding with an error.
+	InvalidUTF8Error
+)
+
+// DecoderOptions configures the JSON input accepted by a Decoder.
+//
+// The zero DecoderOptions decodes RFC 8259 JSON
+// with the same leniency as encoding/json.
+type DecoderOptions struct {
+	// InvalidUTF8 controls the handling of invalid UTF-8 in strings.
+	InvalidUTF8 InvalidUTF8Policy
+
+	// DisallowDuplicateKeys fails decoding objects with repeated keys.
+	// Otherwise the last value for a key is kept.
+	DisallowDuplicateKeys bool
+
+	// DisallowTrailingData fails decoding when any data other than
+	// whitespace follows the first value in the input.
+	// It should not be used when decoding a stream of values.
+	DisallowTrailingData bool
+
+	// MaxDepth limits the nesting depth of arrays and objects.
+	// Zero uses DefaultMaxDepth and a negative value disables the limit.
+	MaxDepth int
+
+	// MaxStringLen limits the length of the encoded strings and keys in bytes.
+	// Zero disables the limit.
+	MaxStringLen int
+
+	// StrictNumbers only accepts numbers matching the RFC 8259 grammar.
+	// Otherwise numbers with leading zeros and a missing integer
+	// or fraction part such as 01, -.5 and 1. are accepted.
+	StrictNumbers bool
+
+	// UseNumber decodes numbers as their exact literal text instead of
+	// float64 so large integers and precise decimals are not rounded.
+	// Numbers only accepted outside of StrictNumbers are still rounded.
+	UseNumber bool
+
+	// OrderedObjects decodes objects which keep their keys in the order
+	// of the input for iteration and encoding.
+	OrderedObjects bool
+}
+
+// DefaultMaxDepth is the nesting depth limit used when MaxDepth is zero.
+const DefaultMaxDepth = 10000
+
+// NewDecoder creates a new JSONG decoder with the options o.
+func (o DecoderOptions) NewDecoder(r io.Reader) *Decoder {
+	if o.MaxDepth == 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	return &Decoder{r: bufio.NewReader(r), opts: o, line: 1}
+}
+
 // Decoder decodes JSONG from JSON.
 type Decoder struct {
-	r *bufio.Reader
+	r    *bufio.Reader
+	opts DecoderOptions
+
+	// Input position used in errors.
+	off       int64
+	line      int
+	lineStart int64
+	path      []any
+	depth     int
+
+	// Arrays and objects opened by Token.
+	tokens []tokenFrame
 }
 
-// NewDecoder creates a new JSONG decoder.
+// NewDecoder creates a new JSONG decoder with the default options.
 func NewDecoder(r io.Reader) *Decoder {
-	return &Decoder{r: bufio.NewReader(r)}
+	return DecoderOptions{}.NewDecoder(r)
+}
+
+// SyntaxError describes malformed JSON input and where it was found.
+type SyntaxError struct {
+	msg string
+	// Offset is the byte offset of the error in the input.
+	Offset int64
+	// Line and Column are the 1-based line and byte column of the error.
+	Line, Column int
+	// Path is the jsong path of the innermost value enclosing the error.
+	Path string
+	// Err is the underlying error such as io.ErrUnexpectedEOF, if any.
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.msg)
+	}
+	return fmt.Sprintf("syntax error at line %d, column %d in %q: %s", e.Line, e.Column, e.Path, e.msg)
+}
+
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
+// position is a position in the input.
+type position struct {
+	off       int64
+	line      int
+	lineStart int64
+}
+
+func (d *Decoder) pos() position { return position{d.off, d.line, d.lineStart} }
+
+// syntaxError returns a SyntaxError at the current position plus n bytes
+// on the current line.
+func (d *Decoder) syntaxError(n int, format string, args ...any) *SyntaxError {
+	return d.syntaxErrorAt(d.pos(), n, format, args...)
+}
+
+// syntaxErrorAt returns a SyntaxError at the position p plus n bytes
+// on the same line.
+func (d *Decoder) syntaxErrorAt(p position, n int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{
+		msg:    fmt.Sprintf(format, args...),
+		Offset: p.off + int64(n),
+		Line:   p.line,
+		Column: int(p.off-p.lineStart) + n + 1,
+		Path:   JoinKey("", d.path...),
+	}
+}
+
+// ioError converts an error from the reader encountered
+// in the middle of a value.
+func (d *Decoder) ioError(err error) error {
+	if err != io.EOF {
+		return err
+	}
+	e := d.syntaxError(0, "unexpected end of JSON input")
+	e.Err = io.ErrUnexpectedEOF
+	return e
+}
+
+func (d *Decoder) advance(bs []byte) {
+	for i, b := range bs {
+		if b == '\n' {
+			d.line++
+			d.lineStart = d.off + int64(i) + 1
+		}
+	}
+	d.off += int64(len(bs))
+}
+
+func (d *Decoder) peekByte() (byte, error) {
+	bs, err := d.r.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return bs[0], nil
+}
+
+func (d *Decoder) discard(n int) {
+	bs, _ := d.r.Peek(n)
+	d.advance(bs)
+	d.r.Discard(len(bs))
 }
 
+func isSpace(b byte) bool { return b == ' ' || b == '\t' || b == '\n' || b == '\r' }
+
 func (d *Decoder) skipWhitespace() error {
 	for {
-		r, _, err := d.r.ReadRune()
+		b, err := d.peekByte()
 		if err != nil {
 			return err
 		}
-		if !unicode.IsSpace(r) {
-			return d.r.UnreadRune()
+		if !isSpace(b) {
+			return nil
 		}
+		d.discard(1)
 	}
 }
 
+// Decode decodes the next JSON value from the input.
+//
+// Decode returns io.EOF when no values remain in the input.
+// Malformed input results in a *SyntaxError while other errors
+// from the underlying reader are returned as is.
 func (d *Decoder) Decode() (any, error) {
+	if len(d.tokens) == 0 {
+		d.path = d.path[:0]
+		d.depth = 0
+		if err := d.skipWhitespace(); err != nil {
+			return nil, err
+		}
+	}
+	return d.elementValue(d.decodeValue, false)
+}
+
+// All returns an iterator over the remaining values in the input.
+//
+// All decodes a stream of concatenated or newline delimited JSON values.
+// Iteration ends at the end of the input or after yielding an error.
+func (d *Decoder) All() iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		for {
+			v, err := d.Decode()
+			if err == io.EOF {
+				return
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (d *Decoder) checkTrailingData() error {
+	err := d.skipWhitespace()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	b, _ := d.peekByte()
+	return d.syntaxError(0, "invalid character %q after top-level value", b)
+}
+
+func (d *Decoder) enter() error {
+	if d.depth++; d.opts.MaxDepth > 0 && d.depth > d.opts.MaxDepth {
+		return d.syntaxError(0, "exceeded max depth of %d", d.opts.MaxDepth)
+	}
+	return nil
+}
+
+func (d *Decoder) exit() { d.depth-- }
+
+func (d *Decoder) decodeValue() (any, error) {
 	if err := d.skipWhitespace(); err != nil {
-		return nil, err
+		return nil, d.ioError(err)
 	}
-	b, err := d.r.Peek(1)
+	b, err := d.peekByte()
 	if err != nil {
-		return nil, err
+		return nil, d.ioError(err)
 	}
-	switch b[0] {
+	switch b {
 	case 'n':
-		return d.decodeNull()
+		return d.decodeLiteral(nullData, null{})
 	case 'f':
-		return d.decodeFalse()
+		return d.decodeLiteral(falseData, boolean(false))
 	case 't':
-		return d.decodeTrue()
-	case '-':
-		d.r.Discard(1)
-		n, err := d.decodeNumber()
-		if err != nil {
-			return nil, err
-		}
-		return -n.(num), nil
-	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return d.decodeLiteral(trueData, boolean(true))
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		return d.decodeNumber()
 	case '"':
 		return d.decodeString()
@@ -62,7 +273,7 @@ func (d *Decoder) Decode() (any, error) {
 	case '{':
 		return d.decodeObject()
 	default:
-		return nil, fmt.Errorf("invalid character %q looking for beginning of value", b[0])
+		return nil, d.syntaxError(0, "invalid character %q looking for beginning of value", b)
 	}
 }
 
@@ -72,58 +283,18 @@ var (
 	trueData  = []byte("true")
 )
 
-func (d *Decoder) decodeNull() (any, error) {
-	bs, err := d.r.Peek(4)
-	if err != nil {
-		return nil, err
-	}
-	if bytes.Equal(bs, nullData) {
-		d.r.Discard(4)
-		return null{}, nil
-	}
-	var j int
-	for i := range bs {
-		if bs[i] != nullData[i] {
-			j = i
-		}
-	}
-	return nil, fmt.Errorf("invalid character %q in literal null (expecting %q)", bs[j], nullData[j])
-}
-
-func (d *Decoder) decodeFalse() (any, error) {
-	bs, err := d.r.Peek(5)
-	if err != nil {
-		return nil, err
-	}
-	if bytes.Equal(bs, falseData) {
-		d.r.Discard(5)
-		return boolean(false), nil
+func (d *Decoder) decodeLiteral(lit []byte, v any) (any, error) {
+	bs, err := d.r.Peek(len(lit))
+	if bytes.Equal(bs, lit) {
+		d.discard(len(lit))
+		return v, nil
 	}
-	var j int
 	for i := range bs {
-		if bs[i] != falseData[i] {
-			j = i
+		if bs[i] != lit[i] {
+			return nil, d.syntaxError(i, "invalid character %q in literal %s (expecting %q)", bs[i], lit, lit[i])
 		}
 	}
-	return nil, fmt.Errorf("invalid character %q in literal false (expecting %q)", bs[j], falseData[j])
-}
-
-func (d *Decoder) decodeTrue() (any, error) {
-	bs, err := d.r.Peek(4)
-	if err != nil {
-		return nil, err
-	}
-	if bytes.Equal(bs, trueData) {
-		d.r.Discard(4)
-		return boolean(true), nil
-	}
-	var j int
-	for i := range bs {
-		if bs[i] != trueData[i] {
-			j = i
-		}
-	}
-	return nil, fmt.Errorf("invalid character %q in literal true (expecting %q)", bs[j], trueData[j])
+	return nil, d.ioError(err)
 }
 
 func (d *Decoder) decodeNumber() (any, error) {
@@ -131,9 +302,13 @@ func (d *Decoder) decodeNumber() (any, error) {
 	var exp bool
 	var expSign bool
 	buf := bytes.NewBuffer(make([]byte, 0, 5))
+	if b, _ := d.peekByte(); b == '-' {
+		d.discard(1)
+		buf.WriteByte(b)
+	}
 loop:
 	for {
-		b, err := d.r.ReadByte()
+		b, err := d.peekByte()
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -143,193 +318,344 @@ loop:
 		switch {
 		case b == '-', b == '+':
 			if dot && !exp {
-				return nil, fmt.Errorf("invalid character %q after decimal point in numeric literal", b)
+				return nil, d.syntaxError(0, "invalid character %q after decimal point in numeric literal", b)
 			}
 			if expSign {
-				return nil, fmt.Errorf("invalid character %q in exponent of numeric literal", b)
+				return nil, d.syntaxError(0, "invalid character %q in exponent of numeric literal", b)
 			}
 			expSign = true
 		case b == 'e', b == 'E':
 			if exp {
-				return nil, fmt.Errorf("invalid character %q in exponent of numeric literal", b)
+				return nil, d.syntaxError(0, "invalid character %q in exponent of numeric literal", b)
 			}
 			exp = true
 		case b == '.':
 			if exp {
-				return nil, fmt.Errorf("invalid character %q in exponent of numeric literal", b)
+				return nil, d.syntaxError(0, "invalid character %q in exponent of numeric literal", b)
 			}
 			if dot {
-				return nil, fmt.Errorf("invalid character %q after decimal point in numeric literal", b)
+				return nil, d.syntaxError(0, "invalid character %q after decimal point in numeric literal", b)
 			}
 			dot = true
 		case '0' <= b && b <= '9':
 		default:
-			d.r.UnreadByte()
 			break loop
 		}
+		d.discard(1)
 		buf.WriteByte(b)
 	}
 
+	if d.opts.StrictNumbers && !validNumber(buf.Bytes()) {
+		return nil, d.syntaxError(-buf.Len(), "invalid numeric literal %q", buf.String())
+	}
+	if d.opts.UseNumber && validNumber(buf.Bytes()) {
+		return decimal(buf.String()), nil
+	}
 	v, err := strconv.ParseFloat(buf.String(), 64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode number %q: %v", buf.String(), err) // Catch parsing issues here.
+		// Catch parsing issues here.
+		return nil, d.syntaxError(-buf.Len(), "failed to decode number %q: %v", buf.String(), err)
 	}
 	return num(v), nil
 }
 
 func (d *Decoder) decodeString() (any, error) {
-	d.r.Discard(1) // '"'
+	start := d.pos()
+	d.discard(1) // '"'
 	buf := bytes.NewBuffer(make([]byte, 0, 16))
 	buf.WriteByte('"')
 	for {
 		bs, err := d.r.ReadSlice('"')
-		if err != nil {
-			return nil, err
-		}
+		d.advance(bs)
 		buf.Write(bs)
-		if len(bs) == 1 || bs[len(bs)-2] != '\\' {
+		if err != nil && err != bufio.ErrBufferFull {
+			return nil, d.ioError(err)
+		}
+		closed := err == nil && !escapedQuote(buf.Bytes())
+		n := buf.Len() - 1 // Opening quote.
+		if closed {
+			n-- // Closing quote.
+		}
+		if max := d.opts.MaxStringLen; max > 0 && n > max {
+			return nil, d.syntaxErrorAt(start, 0, "string literal exceeds max length of %d", max)
+		}
+		if closed {
 			break
 		}
 	}
-	data, err := unquoteInPlace(buf.Bytes())
+	data, err := unquoteInPlace(buf.Bytes(), d.opts.InvalidUTF8)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unquote string %q: %v", buf.String(), err)
+		if e, ok := err.(*SyntaxError); ok {
+			// Errors in strings are relative to the opening quote.
+			return nil, d.syntaxErrorAt(start, int(e.Offset), "%s", e.msg)
+		}
+		return nil, err
 	}
 	// Using string first helps the compiler find the optimization.
 	return str(string(data)), nil
 }
 
+// validNumber reports whether b matches the RFC 8259 number grammar.
+func validNumber(b []byte) bool {
+	digits := func() int {
+		n := 0
+		for ; n < len(b) && '0' <= b[n] && b[n] <= '9'; n++ {
+		}
+		b = b[n:]
+		return n
+	}
+	if len(b) > 0 && b[0] == '-' {
+		b = b[1:]
+	}
+	if len(b) > 0 && b[0] == '0' {
+		b = b[1:]
+	} else if digits() == 0 {
+		return false
+	}
+	if len(b) > 0 && b[0] == '.' {
+		b = b[1:]
+		if digits() == 0 {
+			return false
+		}
+	}
+	if len(b) > 0 && (b[0] == 'e' || b[0] == 'E') {
+		b = b[1:]
+		if len(b) > 0 && (b[0] == '+' || b[0] == '-') {
+			b = b[1:]
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return len(b) == 0
+}
+
+// escapedQuote reports whether the final quote in b is escaped
+// by an odd number of preceding backslashes.
+func escapedQuote(b []byte) bool {
+	n := 0
+	for i := len(b) - 2; i >= 0 && b[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
 func (d *Decoder) decodeArray() (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.exit()
 	res := array{}
-	d.r.Discard(1) // '['
+	d.discard(1) // '['
 	for i := 0; ; i++ {
 		if err := d.skipWhitespace(); err != nil {
-			return nil, err
+			return nil, d.ioError(err)
+		}
+		b, err := d.peekByte()
+		if err != nil {
+			return nil, d.ioError(err)
+		}
+		if b == ']' {
+			d.discard(1)
+			break
 		}
 		if i > 0 {
-			b, err := d.r.ReadByte()
-			if err != nil {
-				return nil, err
-			}
-			if b == ']' {
-				break
-			}
 			if b != ',' {
-				return nil, fmt.Errorf("invalid character %q after array element", b)
-			}
-		} else {
-			b, err := d.r.Peek(1)
-			if err != nil {
-				return nil, err
-			}
-			if b[0] == ']' {
-				d.r.Discard(1)
-				break
+				return nil, d.syntaxError(0, "invalid character %q after array element", b)
 			}
+			d.discard(1)
 		}
-		e, err := d.Decode()
+		d.path = append(d.path, int64(i))
+		e, err := d.decodeValue()
 		if err != nil {
 			return nil, err
 		}
+		d.path = d.path[:len(d.path)-1]
 		res = append(res, e)
 	}
 	return res, nil
 }
 
 func (d *Decoder) decodeObject() (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.exit()
 	res := object{}
-	d.r.Discard(1) // '{'
+	var keys []string
+	d.discard(1) // '{'
 	for i := 0; ; i++ {
 		if err := d.skipWhitespace(); err != nil {
-			return nil, err
+			return nil, d.ioError(err)
 		}
-		bs, err := d.r.Peek(1)
+		b, err := d.peekByte()
 		if err != nil {
-			return nil, err
+			return nil, d.ioError(err)
 		}
-		if bs[0] == '}' {
-			d.r.Discard(1)
+		if b == '}' {
+			d.discard(1)
 			break
 		}
 		if i > 0 {
-			if bs[0] != ',' {
-				return nil, fmt.Errorf("invalid character %q after array element", bs[0])
+			if b != ',' {
+				return nil, d.syntaxError(0, "invalid character %q after object key:value pair", b)
 			}
-			d.r.Discard(1)
+			d.discard(1)
 			if err := d.skipWhitespace(); err != nil {
-				return nil, err
+				return nil, d.ioError(err)
 			}
-			bs, err = d.r.Peek(1)
-			if err != nil {
-				return nil, err
+			if b, err = d.peekByte(); err != nil {
+				return nil, d.ioError(err)
 			}
 		}
-		if bs[0] != '"' {
-			return nil, fmt.Errorf("invalid character %q looking for beginning of object key string", bs[0])
+		if b != '"' {
+			return nil, d.syntaxError(0, "invalid character %q looking for beginning of object key string", b)
 		}
+		keyPos := d.pos()
 		k, err := d.decodeString()
 		if err != nil {
 			return nil, err
 		}
+		_, dup := res[string(k.(str))]
+		if dup && d.opts.DisallowDuplicateKeys {
+			return nil, d.syntaxErrorAt(keyPos, 0, "duplicate object key %q", k)
+		}
 		if err := d.skipWhitespace(); err != nil {
-			return nil, err
+			return nil, d.ioError(err)
 		}
-		b, err := d.r.ReadByte()
-		if err != nil {
-			return nil, err
+		if b, err = d.peekByte(); err != nil {
+			return nil, d.ioError(err)
 		}
 		if b != ':' {
-			return nil, fmt.Errorf("invalid character %q after object key", b)
+			return nil, d.syntaxError(0, "invalid character %q after object key", b)
 		}
-		v, err := d.Decode()
+		d.discard(1)
+		d.path = append(d.path, string(k.(str)))
+		v, err := d.decodeValue()
 		if err != nil {
 			return nil, err
 		}
-		res[string(k.(str))] = v // Repeat keys are ok.
+		d.path = d.path[:len(d.path)-1]
+		if !dup && d.opts.OrderedObjects {
+			keys = append(keys, string(k.(str)))
+		}
+		res[string(k.(str))] = v // Repeat keys are ok unless disallowed.
+	}
+	if d.opts.OrderedObjects {
+		return &orderedObject{keys: keys, m: res}, nil
 	}
 	return res, nil
 }
 
-var emptyStr = []byte(`""`)
-
-func unquoteInPlace(b []byte) ([]byte, error) {
-	if len(b) < 2 {
+// unquoteInPlace decodes the quoted JSON string b in place.
+//
+// It decodes all RFC 8259 escape sequences including UTF-16 surrogate
+// pairs and handles invalid UTF-8 according to policy.
+// The result may be reallocated only when invalid UTF-8 is replaced.
+// Errors are returned as a *SyntaxError with the Offset into b.
+func unquoteInPlace(b []byte, policy InvalidUTF8Policy) ([]byte, error) {
+	if len(b) < 2 || b[0] != '"' {
 		return nil, strconv.ErrSyntax
 	}
-	if len(b) == 2 && bytes.Equal(b, emptyStr) {
-		return []byte{}, nil
-	}
-	b[0] = b[1]
-	i := 2
-	if b[0] == '\\' {
-		i++
-		b[0] = b[2]
-	}
-	end := 1
-	escape := false
-	for ; i < len(b); i++ {
-		switch b[i] {
-		case '\\':
-			if escape {
-				escape = false
-				b[end] = b[i]
-				end++
-				continue
+	end := 0
+	for i := 1; i < len(b); {
+		switch c := b[i]; {
+		case c == '"':
+			return validateUTF8(b[:end], policy)
+		case c < 0x20:
+			return nil, unquoteError(i, "invalid character %q in string literal", c)
+		case c != '\\':
+			b[end] = c
+			end++
+			i++
+			continue
+		}
+		if i+1 >= len(b) {
+			return nil, unquoteError(i, "unexpected end of string literal")
+		}
+		c := b[i+1]
+		i += 2
+		switch c {
+		case '"', '\\', '/':
+		case 'b':
+			c = '\b'
+		case 'f':
+			c = '\f'
+		case 'n':
+			c = '\n'
+		case 'r':
+			c = '\r'
+		case 't':
+			c = '\t'
+		case 'u':
+			r, ok := unhex4(b[i:])
+			if !ok {
+				return nil, unquoteError(i, "invalid character in \\u hexadecimal character escape")
 			}
-			escape = true
-		case '"':
-			if escape {
-				escape = false
-				b[end] = b[i]
-				end++
-				continue
+			i += 4
+			if utf16.IsSurrogate(r) {
+				r2, ok := rune(-1), false
+				if i+1 < len(b) && b[i] == '\\' && b[i+1] == 'u' {
+					r2, ok = unhex4(b[i+2:])
+				}
+				if dec := utf16.DecodeRune(r, r2); ok && dec != utf8.RuneError {
+					r = dec
+					i += 6
+				} else if policy == InvalidUTF8Error {
+					return nil, unquoteError(i-6, "invalid unpaired surrogate \\u%04x in string literal", r)
+				} else {
+					r = utf8.RuneError
+				}
 			}
-			return b[:end], nil
+			end += utf8.EncodeRune(b[end:], r)
+			continue
 		default:
-			escape = false
-			b[end] = b[i]
-			end++
+			return nil, unquoteError(i-1, "invalid character %q in string escape code", c)
+		}
+		b[end] = c
+		end++
+	}
+	return nil, unquoteError(len(b), "unexpected end of string literal")
+}
+
+func unquoteError(i int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{msg: fmt.Sprintf(format, args...), Offset: int64(i)}
+}
+
+func unhex4(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[:4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c -= 'a' - 10
+		case 'A' <= c && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
 		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// validateUTF8 checks b for invalid UTF-8 according to policy.
+func validateUTF8(b []byte, policy InvalidUTF8Policy) ([]byte, error) {
+	if utf8.Valid(b) {
+		return b, nil
+	}
+	if policy == InvalidUTF8Error {
+		return nil, unquoteError(0, "invalid UTF-8 in string literal")
 	}
-	return nil, io.EOF
+	res := make([]byte, 0, len(b)+8)
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		res = utf8.AppendRune(res, r)
+		b = b[size:]
+	}
+	return res, nil
 }
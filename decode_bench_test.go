@@ -0,0 +1,40 @@
+package jsong
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func benchDecodeInput() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"items": [`)
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"id": %d, "name": "item %d", "tags": ["a", "b", "c"], "price": %d.5}`, i, i, i)
+	}
+	buf.WriteString(`], "total": 1000}`)
+	return buf.Bytes()
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := benchDecodeInput()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodePaths(b *testing.B) {
+	data := benchDecodeInput()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data)).DecodePaths("total"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
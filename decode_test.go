@@ -1,6 +1,11 @@
 package jsong
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -247,7 +252,7 @@ func TestDecodeNestedObject(t *testing.T) {
 }
 
 func TestUnquoteInPlace(t *testing.T) {
-	got, err := unquoteInPlace([]byte(`"hello \"world\"!"`))
+	got, err := unquoteInPlace([]byte(`"hello \"world\"!"`), InvalidUTF8Replace)
 	want := []byte(`hello "world"!`)
 
 	gotErr := err != nil
@@ -262,7 +267,7 @@ func TestUnquoteInPlace(t *testing.T) {
 }
 
 func TestUnquoteInPlaceQuotedEmpty(t *testing.T) {
-	got, err := unquoteInPlace([]byte(`""`))
+	got, err := unquoteInPlace([]byte(`""`), InvalidUTF8Replace)
 	want := []byte{}
 
 	gotErr := err != nil
@@ -275,3 +280,306 @@ func TestUnquoteInPlaceQuotedEmpty(t *testing.T) {
 		t.Errorf("UnquoteInPlace(): got diff:\n%s", diff)
 	}
 }
+
+func TestUnquoteInPlaceEscapes(t *testing.T) {
+	got, err := unquoteInPlace([]byte(`"\"\\\/\b\f\n\r\t\u00e9\ud83d\ude39"`), InvalidUTF8Replace)
+	want := []byte("\"\\/\b\f\n\r\t\u00e9\U0001f639")
+
+	gotErr := err != nil
+	wantErr := false
+	if gotErr != wantErr {
+		t.Fatalf("UnquoteInPlace(): got err = %v, want err = %v", err, wantErr)
+	}
+
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("UnquoteInPlace(): got diff:\n%s", diff)
+	}
+}
+
+func TestDecodeStringEndsWithEscapedBackslash(t *testing.T) {
+	got, err := NewDecoder(strings.NewReader(`["a\\", "b"]`)).Decode()
+
+	want := array{str(`a\`), str("b")}
+	wantErr := false
+
+	gotErr := err != nil
+	if wantErr != gotErr {
+		t.Fatalf("Decode(): want err = %v, got err = %v", wantErr, err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Decode(): got diff:\n%s", diff)
+	}
+}
+
+func TestDecodeStringInvalidUTF8Replace(t *testing.T) {
+	got, err := NewDecoder(strings.NewReader("\"a\xffb\\udc00\"")).Decode()
+
+	want := str("a\ufffdb\ufffd")
+	wantErr := false
+
+	gotErr := err != nil
+	if wantErr != gotErr {
+		t.Fatalf("Decode(): want err = %v, got err = %v", wantErr, err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Decode(): got diff:\n%s", diff)
+	}
+}
+
+func TestDecodeStringInvalidUTF8Error(t *testing.T) {
+	for _, input := range []string{"\"a\xffb\"", `"\ud800"`, `"\udc00\ud800"`} {
+		_, err := DecoderOptions{InvalidUTF8: InvalidUTF8Error}.NewDecoder(strings.NewReader(input)).Decode()
+		if err == nil {
+			t.Errorf("Decode(%q): want err = true, got err = %v", input, err)
+		}
+	}
+}
+
+// TestDecodeConformance runs the conformance corpus in testdata/parsing.
+//
+// The corpus follows the naming of JSONTestSuite: files prefixed with y_
+// must be accepted, n_ must be rejected and i_ are implementation defined
+// but must not panic. Files are decoded with the strict options
+// that reject trailing data and nonconforming numbers.
+func TestDecodeConformance(t *testing.T) {
+	opts := DecoderOptions{DisallowTrailingData: true, StrictNumbers: true}
+	files, err := filepath.Glob(filepath.Join("testdata", "parsing", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = opts.NewDecoder(bytes.NewReader(data)).Decode()
+			switch name[0] {
+			case 'y':
+				if err != nil {
+					t.Errorf("Decode(%q): want err = false, got err = %v", name, err)
+				}
+			case 'n':
+				if err == nil {
+					t.Errorf("Decode(%q): want err = true, got err = %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeSyntaxError(t *testing.T) {
+	input := "{\n  \"a\": [1, 2],\n  \"b\": {\"c\": [true, nul]}\n}"
+
+	_, err := NewDecoder(strings.NewReader(input)).Decode()
+
+	var got *SyntaxError
+	if !errors.As(err, &got) {
+		t.Fatalf("Decode(): want *SyntaxError, got err = %v", err)
+	}
+
+	want := &SyntaxError{
+		msg:    `invalid character ']' in literal null (expecting 'l')`,
+		Offset: 40,
+		Line:   3,
+		Column: 24,
+		Path:   "b.c.1",
+	}
+
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(SyntaxError{})); diff != "" {
+		t.Errorf("Decode(): got diff:\n%s", diff)
+	}
+}
+
+func TestDecodeSyntaxErrorInString(t *testing.T) {
+	input := "[\n\"ok\", \"bad \\x escape\"]"
+
+	_, err := NewDecoder(strings.NewReader(input)).Decode()
+
+	var got *SyntaxError
+	if !errors.As(err, &got) {
+		t.Fatalf("Decode(): want *SyntaxError, got err = %v", err)
+	}
+
+	want := &SyntaxError{
+		msg:    `invalid character 'x' in string escape code`,
+		Offset: 14,
+		Line:   2,
+		Column: 13,
+		Path:   "1",
+	}
+
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(SyntaxError{})); diff != "" {
+		t.Errorf("Decode(): got diff:\n%s", diff)
+	}
+}
+
+func TestDecodeSyntaxErrorUnexpectedEOF(t *testing.T) {
+	_, err := NewDecoder(strings.NewReader(`{"a": [1, 2`)).Decode()
+
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Decode(): want *SyntaxError, got err = %v", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Decode(): want io.ErrUnexpectedEOF, got err = %v", err)
+	}
+}
+
+func TestDecodeEOF(t *testing.T) {
+	_, err := NewDecoder(strings.NewReader(" \n")).Decode()
+
+	if err != io.EOF {
+		t.Errorf("Decode(): want io.EOF, got err = %v", err)
+	}
+}
+
+type decoderOptionsTestCase struct {
+	name    string
+	opts    DecoderOptions
+	input   string
+	want    any
+	wantErr bool
+}
+
+func (tc decoderOptionsTestCase) runTest(t *testing.T) {
+	t.Helper()
+	got, err := tc.opts.NewDecoder(strings.NewReader(tc.input)).Decode()
+	if gotErr := err != nil; gotErr != tc.wantErr {
+		t.Fatalf("Decode(%q): got err = %v, want err = %v", tc.name, err, tc.wantErr)
+	}
+	if tc.wantErr {
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Decode(%q): want *SyntaxError, got err = %v", tc.name, err)
+		}
+		return
+	}
+	if diff := cmp.Diff(tc.want, got); diff != "" {
+		t.Errorf("Decode(%q): got diff:\n%s", tc.name, diff)
+	}
+}
+
+func TestDecoderOptions(t *testing.T) {
+	for _, tc := range []decoderOptionsTestCase{{
+		name:  "duplicate keys allowed",
+		input: `{"a": 1, "a": 2}`,
+		want:  object{"a": num(2)},
+	}, {
+		name:    "duplicate keys disallowed",
+		opts:    DecoderOptions{DisallowDuplicateKeys: true},
+		input:   `{"a": 1, "a": 2}`,
+		wantErr: true,
+	}, {
+		name:  "trailing data allowed",
+		input: `[1] x`,
+		want:  array{num(1)},
+	}, {
+		name:    "trailing data disallowed",
+		opts:    DecoderOptions{DisallowTrailingData: true},
+		input:   `[1] x`,
+		wantErr: true,
+	}, {
+		name:  "trailing whitespace",
+		opts:  DecoderOptions{DisallowTrailingData: true},
+		input: "[1] \n",
+		want:  array{num(1)},
+	}, {
+		name:  "max depth",
+		opts:  DecoderOptions{MaxDepth: 2},
+		input: `[{}]`,
+		want:  array{object{}},
+	}, {
+		name:    "max depth exceeded",
+		opts:    DecoderOptions{MaxDepth: 2},
+		input:   `[{"a": []}]`,
+		wantErr: true,
+	}, {
+		name:    "default max depth exceeded",
+		input:   strings.Repeat("[", DefaultMaxDepth+1),
+		wantErr: true,
+	}, {
+		name:  "max string length",
+		opts:  DecoderOptions{MaxStringLen: 3},
+		input: `"abc"`,
+		want:  str("abc"),
+	}, {
+		name:    "max string length exceeded",
+		opts:    DecoderOptions{MaxStringLen: 3},
+		input:   `"abcd"`,
+		wantErr: true,
+	}, {
+		name:    "max key length exceeded",
+		opts:    DecoderOptions{MaxStringLen: 3},
+		input:   `{"abcd": 1}`,
+		wantErr: true,
+	}, {
+		name:  "lenient leading zero",
+		input: `012`,
+		want:  num(12),
+	}, {
+		name:    "strict leading zero",
+		opts:    DecoderOptions{StrictNumbers: true},
+		input:   `012`,
+		wantErr: true,
+	}, {
+		name:  "strict number",
+		opts:  DecoderOptions{StrictNumbers: true},
+		input: `-0.5e+3`,
+		want:  num(-500),
+	}, {
+		name:    "number expression",
+		input:   `1-2`,
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
+
+func TestDecoderAll(t *testing.T) {
+	input := "{\"a\": 1}\n{\"a\": 2}\n\n[3] \"4\"\n"
+
+	var got []any
+	for v, err := range NewDecoder(strings.NewReader(input)).All() {
+		if err != nil {
+			t.Fatalf("All(): got err = %v, want err = false", err)
+		}
+		got = append(got, v)
+	}
+
+	want := []any{object{"a": num(1)}, object{"a": num(2)}, array{num(3)}, str("4")}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("All(): got diff:\n%s", diff)
+	}
+}
+
+func TestDecoderAllError(t *testing.T) {
+	input := "{\"a\": 1}\n{\"a\": }\n{\"a\": 3}\n"
+
+	var got []any
+	var gotErr error
+	for v, err := range NewDecoder(strings.NewReader(input)).All() {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, v)
+	}
+
+	want := []any{object{"a": num(1)}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("All(): got diff:\n%s", diff)
+	}
+
+	var syntaxErr *SyntaxError
+	if !errors.As(gotErr, &syntaxErr) || syntaxErr.Line != 2 {
+		t.Errorf("All(): want *SyntaxError on line 2, got err = %v", gotErr)
+	}
+}
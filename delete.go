@@ -2,23 +2,104 @@ package jsong
 
 // Delete the path from the value v and return the result.
 //
-// The empty path returns nil.
+// The empty path returns nil. Invalid paths delete nothing.
+// Values which ValueOf cannot convert return nil.
+// Paths may also be JSON Pointers. Negative indices count from
+// the end of arrays and slices like "1:3" delete the rest of the
+// path from each of the selected elements.
+//
+// Delete modifies the objects and arrays of jsong values in place.
+// Go values are converted with ValueOf and are not modified.
+// See DeleteCopy. Persistent values are never modified. See Freeze.
 func Delete(v any, path string) any {
-	if path == "" || v == nil || v == (null{}) {
+	rv := valueOf(v)
+	if rv == nil {
+		return nil
+	}
+	p, ok := parsePathIn(rv, path)
+	if !ok {
+		return rv
+	}
+	return DeletePath(rv, p)
+}
+
+// DeletePath is like Delete but takes a parsed Path.
+func DeletePath(v any, p Path) any {
+	if len(p) == 0 || v == nil || v == (null{}) {
 		return null{}
 	}
-	rv, ok := v.(valueInterface)
+	rv := valueOf(v)
+	if rv == nil {
+		return nil
+	}
+	return deleteImpl(rv, p)
+}
+
+// DeleteCopy is like Delete but leaves v unchanged. Only the objects
+// and arrays along the path are copied and the result shares the
+// other values with v. Use Clone for an independent copy.
+func DeleteCopy(v any, path string) any {
+	rv := valueOf(v)
+	if rv == nil {
+		return nil
+	}
+	p, ok := parsePathIn(rv, path)
 	if !ok {
-		rv = ValueOf(v).(valueInterface)
+		return rv
+	}
+	if len(p) == 0 || rv == (null{}) {
+		return null{}
+	}
+	return deleteCopy(rv, p)
+}
+
+func deleteCopy(rv valueInterface, p Path) valueInterface {
+	if pv, ok := rv.(persistentValue); ok {
+		return deletePersistent(pv, p)
+	}
+	cloned := false
+	for _, k := range resolveKeys(rv, p[0]) {
+		next, ok := rv.Get(k)
+		if !ok {
+			continue
+		}
+		if !cloned {
+			rv, cloned = shallowClone(rv), true
+		}
+		if len(p) == 1 {
+			rv.Delete(k)
+		} else {
+			rv.Put(k, deleteCopy(next, p[1:]))
+		}
 	}
-	return deleteImpl(rv, path)
+	return rv
+}
+
+func deleteImpl(rv valueInterface, p Path) valueInterface {
+	if pv, ok := rv.(persistentValue); ok {
+		return deletePersistent(pv, p)
+	}
+	for _, k := range resolveKeys(rv, p[0]) {
+		if len(p) == 1 {
+			rv.Delete(k)
+		} else if next, ok := rv.Get(k); ok {
+			rv.Put(k, deleteImpl(next, p[1:]))
+		}
+	}
+	return rv
 }
 
-func deleteImpl(rv valueInterface, path string) valueInterface {
-	if head, tail, leaf := CutKey(path); leaf {
-		rv.Delete(head)
-	} else if next, ok := rv.Get(head); ok {
-		rv.Put(head, deleteImpl(next, tail))
+// deletePersistent returns the persistent value rv without the path.
+func deletePersistent(rv persistentValue, p Path) valueInterface {
+	for _, k := range resolveKeys(rv, p[0]) {
+		if len(p) == 1 {
+			rv = rv.without(k).(persistentValue)
+			continue
+		}
+		if next, ok := rv.Get(k); ok {
+			res, _ := rv.with(k, deleteImpl(next, p[1:]))
+			rv = res.(persistentValue)
+		}
 	}
 	return rv
 }
@@ -54,6 +54,37 @@ func TestDeleteSlice(t *testing.T) {
 	}
 }
 
+func TestDeleteNegativeIndexAndSlice(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want any
+	}{
+		{path: "a.-1", want: object{"a": array{object{"b": num(1)}, object{"b": num(2)}, nil}}},
+		{path: "a.-4", want: object{"a": array{object{"b": num(1)}, object{"b": num(2)}, object{"b": num(3)}}}},
+		{path: "a.1:", want: object{"a": array{object{"b": num(1)}, nil, nil}}},
+		{path: "a.:-1.b", want: object{"a": array{object{}, object{}, object{"b": num(3)}}}},
+		{path: "a.b.1:", want: object{"a": array{object{"b": num(1)}, object{"b": num(2)}, object{"b": num(3)}}}},
+	} {
+		newInput := func() object {
+			return object{"a": array{object{"b": num(1)}, object{"b": num(2)}, object{"b": num(3)}}}
+		}
+		input := newInput()
+
+		if diff := cmp.Diff(tc.want, DeleteCopy(input, tc.path)); diff != "" {
+			t.Errorf("DeleteCopy(%q): got diff:\n%v", tc.path, diff)
+		}
+		if diff := cmp.Diff(newInput(), input); diff != "" {
+			t.Errorf("DeleteCopy(%q): modified input:\n%v", tc.path, diff)
+		}
+		if diff := cmp.Diff(tc.want, Delete(input, tc.path)); diff != "" {
+			t.Errorf("Delete(%q): got diff:\n%v", tc.path, diff)
+		}
+		if diff := cmp.Diff(tc.want, Thaw(Delete(Freeze(newInput()), tc.path))); diff != "" {
+			t.Errorf("Delete(Freeze(), %q): got diff:\n%v", tc.path, diff)
+		}
+	}
+}
+
 func TestDeletePath(t *testing.T) {
 	m := map[string]any{"a": []any{1.0}}
 
@@ -0,0 +1,258 @@
+package jsong
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"golang.org/x/exp/maps"
+)
+
+// EncoderOptions configures the JSON output of an Encoder.
+//
+// The zero EncoderOptions writes compact JSON
+// in the same format as encoding/json but without HTML escaping.
+type EncoderOptions struct {
+	// Prefix and Indent enable indented output when either is nonempty.
+	// Each element of an array or object begins on a new line
+	// beginning with Prefix followed by copies of Indent
+	// according to the nesting depth.
+	Prefix string
+	Indent string
+
+	// EscapeHTML escapes the characters <, > and & in strings
+	// so the output can be safely embedded in HTML.
+	EscapeHTML bool
+
+	// SortKeys writes object keys in sorted order.
+	// Otherwise object keys are written in iteration order
+	// which is insertion order for ordered objects.
+	SortKeys bool
+
+	// FloatFormat is the format passed to strconv.AppendFloat for numbers.
+	// The zero value selects the format used by encoding/json.
+	FloatFormat byte
+
+	// FloatPrecision is the precision passed to strconv.AppendFloat
+	// when FloatFormat is set. Zero selects the smallest number of digits
+	// needed to represent the value exactly.
+	FloatPrecision int
+}
+
+// NewEncoder creates a new JSONG encoder with the options o.
+func (o EncoderOptions) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, opts: o}
+}
+
+// Encoder encodes JSONG as JSON.
+type Encoder struct {
+	w    io.Writer
+	opts EncoderOptions
+	buf  []byte
+}
+
+// NewEncoder creates a new JSONG encoder with the default options.
+func NewEncoder(w io.Writer) *Encoder {
+	return EncoderOptions{}.NewEncoder(w)
+}
+
+// Encode writes the JSON encoding of v followed by a newline.
+//
+// Values which are not jsong values are first converted with ValueOfErr.
+// Encode returns an error for numbers which are infinite or NaN.
+func (e *Encoder) Encode(v any) error {
+	if _, ok := v.(valueInterface); !ok && v != nil {
+		var err error
+		if v, err = ValueOfErr(v); err != nil {
+			return err
+		}
+	}
+	buf, err := e.appendValue(e.buf[:0], v, 0)
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+	e.buf = buf
+	_, err = e.w.Write(buf)
+	return err
+}
+
+func (e *Encoder) appendValue(b []byte, v any, depth int) ([]byte, error) {
+	switch v := v.(type) {
+	case nil, null:
+		return append(b, nullData...), nil
+	case boolean:
+		if v {
+			return append(b, trueData...), nil
+		}
+		return append(b, falseData...), nil
+	case num:
+		return e.appendNum(b, float64(v))
+	case decimal:
+		return append(b, v...), nil
+	case str:
+		return appendString(b, string(v), e.opts.EscapeHTML), nil
+	case array:
+		return e.appendArray(b, v, depth)
+	case object:
+		return e.appendObject(b, v, maps.Keys(v), depth)
+	case *orderedObject:
+		return e.appendObject(b, v.m, v.keys, depth)
+	case *persistentArray:
+		return e.appendArray(b, materialize(v).(array), depth)
+	case *persistentObject:
+		o := materialize(v).(object)
+		return e.appendObject(b, o, maps.Keys(o), depth)
+	default:
+		return nil, fmt.Errorf("Encode: unexpected value type %T", v)
+	}
+}
+
+func (e *Encoder) appendNum(b []byte, f float64) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("Encode: unsupported number value: %v", f)
+	}
+	if e.opts.FloatFormat != 0 {
+		prec := e.opts.FloatPrecision
+		if prec == 0 {
+			prec = -1
+		}
+		return strconv.AppendFloat(b, f, e.opts.FloatFormat, prec, 64), nil
+	}
+	// Use the same format as encoding/json.
+	// See pkg.go.dev/encoding/json#floatEncoder for details.
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+func (e *Encoder) appendArray(b []byte, a array, depth int) ([]byte, error) {
+	if len(a) == 0 {
+		return append(b, "[]"...), nil
+	}
+	b = append(b, '[')
+	for i, v := range a {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = e.appendNewline(b, depth+1)
+		var err error
+		if b, err = e.appendValue(b, v, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	b = e.appendNewline(b, depth)
+	return append(b, ']'), nil
+}
+
+// appendObject appends the object o with its keys in the given order.
+func (e *Encoder) appendObject(b []byte, o object, keys []string, depth int) ([]byte, error) {
+	if len(o) == 0 {
+		return append(b, "{}"...), nil
+	}
+	if e.opts.SortKeys {
+		keys = slices.Sorted(slices.Values(keys))
+	}
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = e.appendNewline(b, depth+1)
+		b = appendString(b, k, e.opts.EscapeHTML)
+		b = append(b, ':')
+		if e.indented() {
+			b = append(b, ' ')
+		}
+		var err error
+		if b, err = e.appendValue(b, o[k], depth+1); err != nil {
+			return nil, err
+		}
+	}
+	b = e.appendNewline(b, depth)
+	return append(b, '}'), nil
+}
+
+func (e *Encoder) indented() bool { return e.opts.Prefix != "" || e.opts.Indent != "" }
+
+func (e *Encoder) appendNewline(b []byte, depth int) []byte {
+	if !e.indented() {
+		return b
+	}
+	b = append(b, '\n')
+	b = append(b, e.opts.Prefix...)
+	for i := 0; i < depth; i++ {
+		b = append(b, e.opts.Indent...)
+	}
+	return b
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends the quoted JSON string s to b.
+//
+// Invalid UTF-8 is replaced with the escaped Unicode replacement character.
+func appendString(b []byte, s string, escapeHTML bool) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!escapeHTML || (c != '<' && c != '>' && c != '&')) {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but not valid JavaScript.
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
@@ -0,0 +1,126 @@
+package jsong
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type encodeTestCase struct {
+	name    string
+	opts    EncoderOptions
+	input   any
+	want    string
+	wantErr bool
+}
+
+func (tc encodeTestCase) runTest(t *testing.T) {
+	t.Helper()
+	var sb strings.Builder
+	err := tc.opts.NewEncoder(&sb).Encode(tc.input)
+	if gotErr := err != nil; gotErr != tc.wantErr {
+		t.Fatalf("Encode(%q): got err = %v, want err = %v", tc.name, err, tc.wantErr)
+	}
+	if tc.wantErr {
+		return
+	}
+	if diff := cmp.Diff(tc.want, sb.String()); diff != "" {
+		t.Errorf("Encode(%q): got diff:\n%s", tc.name, diff)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []encodeTestCase{{
+		name: "nil",
+		want: "null\n",
+	}, {
+		name:  "null",
+		input: null{},
+		want:  "null\n",
+	}, {
+		name:  "bool",
+		input: boolean(true),
+		want:  "true\n",
+	}, {
+		name:  "number",
+		input: num(-1.5),
+		want:  "-1.5\n",
+	}, {
+		name:  "large number",
+		input: num(1e21),
+		want:  "1e+21\n",
+	}, {
+		name:  "small number",
+		input: num(1e-7),
+		want:  "1e-7\n",
+	}, {
+		name:    "NaN",
+		input:   num(math.NaN()),
+		wantErr: true,
+	}, {
+		name:  "float format",
+		opts:  EncoderOptions{FloatFormat: 'f', FloatPrecision: 2},
+		input: num(1),
+		want:  "1.00\n",
+	}, {
+		name:  "string escapes",
+		input: str("\"a\\b\"\n\t\x01\u2028"),
+		want:  `"\"a\\b\"\n\t\u0001\u2028"` + "\n",
+	}, {
+		name:  "invalid UTF-8",
+		input: str("a\xffb"),
+		want:  `"a\ufffdb"` + "\n",
+	}, {
+		name:  "no HTML escaping",
+		input: str("<a&b>"),
+		want:  `"<a&b>"` + "\n",
+	}, {
+		name:  "HTML escaping",
+		opts:  EncoderOptions{EscapeHTML: true},
+		input: str("<a&b>"),
+		want:  `"\u003ca\u0026b\u003e"` + "\n",
+	}, {
+		name:  "array",
+		input: array{num(1), str("2"), boolean(true), nil},
+		want:  `[1,"2",true,null]` + "\n",
+	}, {
+		name:  "sorted object",
+		opts:  EncoderOptions{SortKeys: true},
+		input: object{"c": num(3), "a": num(1), "b": array{}},
+		want:  `{"a":1,"b":[],"c":3}` + "\n",
+	}, {
+		name:  "indented",
+		opts:  EncoderOptions{Indent: "  ", SortKeys: true},
+		input: object{"a": array{num(1), object{}}, "b": null{}},
+		want:  "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": null\n}\n",
+	}, {
+		name:  "Go value",
+		opts:  EncoderOptions{SortKeys: true},
+		input: map[string]any{"a": []int{1, 2}},
+		want:  `{"a":[1,2]}` + "\n",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	input := `{"a":[1,2.5,-3e-7,"x\"y"],"b":{"c":null,"d":true}}`
+
+	v, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("Decode(): got err = %v, want err = false", err)
+	}
+
+	var sb strings.Builder
+	if err := (EncoderOptions{SortKeys: true}).NewEncoder(&sb).Encode(v); err != nil {
+		t.Fatalf("Encode(): got err = %v, want err = false", err)
+	}
+
+	if diff := cmp.Diff(input+"\n", sb.String()); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+}
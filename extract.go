@@ -1,35 +1,70 @@
 package jsong
 
 // Extract the path from the value v or return nil if not present.
+// Values which ValueOf cannot convert return nil.
 //
 // JSON paths are field or array indices joined by the dot character.
 // The empty path returns the input value processed by ValueOf.
+// See CutKey for the path syntax. Paths may also be JSON Pointers.
+//
+// Negative indices count from the end of arrays. Slices like "1:3"
+// return an array of the values of the rest of the path in each of
+// the selected elements where present.
 func Extract(v any, path string) any {
-	var rv valueInterface
-	if x, ok := v.(valueInterface); ok {
-		rv = x
-	} else if v := ValueOf(v); v != nil {
-		rv = v.(valueInterface)
-	} else {
+	rv := valueOf(v)
+	if rv == nil {
+		return nil
+	}
+	p, ok := parsePathIn(rv, path)
+	if !ok {
 		return nil
 	}
-	return extractRec(rv, path)
+	return extractRec(rv, p)
 }
 
-func extractRec(rv valueInterface, path string) valueInterface {
-	if path == "" {
-		return rv
-	}
-	head, tail, leaf := CutKey(path)
-	if head == "" {
+// ExtractPath is like Extract but takes a parsed Path.
+func ExtractPath(v any, p Path) any {
+	rv := valueOf(v)
+	if rv == nil {
 		return nil
 	}
-	if !leaf && tail == "" {
-		return nil
+	return extractRec(rv, p)
+}
+
+func extractRec(rv valueInterface, p Path) valueInterface {
+	for i, k := range p {
+		if s, ok := k.(Slice); ok {
+			return extractSlice(rv, s, p[i+1:])
+		}
+		keys := resolveKeys(rv, k)
+		if len(keys) == 0 {
+			return nil
+		}
+		var ok bool
+		if rv, ok = rv.Get(keys[0]); !ok {
+			return nil
+		}
 	}
-	rv, ok := rv.Get(head)
-	if !ok {
+	return rv
+}
+
+// extractSlice returns the array of the values of the path p
+// in the elements of the slice s of the array rv.
+func extractSlice(rv valueInterface, s Slice, p Path) valueInterface {
+	if KindOf(rv) != KindArray {
 		return nil
 	}
-	return extractRec(rv, tail)
+	keys := resolveKeys(rv, s)
+	res := make(array, 0, len(keys))
+	for _, k := range keys {
+		if e, ok := rv.Get(k); ok {
+			if e = extractRec(e, p); e != nil {
+				res = append(res, e)
+			}
+		}
+	}
+	if _, ok := rv.(persistentValue); ok {
+		return freeze(res)
+	}
+	return res
 }
@@ -81,3 +81,18 @@ func BenchmarkExtractBaseline(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkExtractPath(b *testing.B) {
+	paths := make([]Path, len(benchExtractPaths))
+	for i, path := range benchExtractPaths {
+		paths[i] = Must(ParsePath(path))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, v := range benchExtractValues {
+			for _, p := range paths {
+				ExtractPath(v, p)
+			}
+		}
+	}
+}
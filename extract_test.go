@@ -6,6 +6,60 @@ import (
 	"github.com/google/go-cmp/cmp"
 )
 
+func TestExtractNegativeIndexAndSlice(t *testing.T) {
+	v := object{"a": array{
+		object{"id": num(1)},
+		object{"id": num(2), "x": boolean(true)},
+		object{"id": num(3)},
+	}}
+
+	for _, tc := range []struct {
+		path string
+		want any
+	}{
+		{path: "a.-1.id", want: num(3)},
+		{path: "a.-3.id", want: num(1)},
+		{path: "a.-4", want: nil},
+		{path: "a.1:.id", want: array{num(2), num(3)}},
+		{path: "a.:-1.id", want: array{num(1), num(2)}},
+		{path: "a.-2:", want: array{object{"id": num(2), "x": boolean(true)}, object{"id": num(3)}}},
+		{path: "a.:.x", want: array{boolean(true)}},
+		{path: "a.2:1", want: array{}},
+		{path: "a.5:", want: array{}},
+		{path: "a.id.1:", want: nil},
+		{path: "-1", want: nil},
+		{path: "1:", want: nil},
+	} {
+		got := Extract(v, tc.path)
+
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Extract(%q): got diff:\n%v", tc.path, diff)
+		}
+	}
+}
+
+func TestExtractObjectKeysLikeIndices(t *testing.T) {
+	v := object{"-1": num(1), "1:3": num(2), "0": num(3)}
+
+	for _, tc := range []struct {
+		path string
+		want any
+	}{
+		{path: "-1", want: nil},
+		{path: "1:3", want: nil},
+		{path: "0", want: nil},
+		{path: JoinKey("", "-1"), want: num(1)},
+		{path: JoinKey("", "1:3"), want: num(2)},
+		{path: JoinKey("", "0"), want: num(3)},
+	} {
+		got := Extract(v, tc.path)
+
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Extract(%q): got diff:\n%v", tc.path, diff)
+		}
+	}
+}
+
 func TestExtractNil(t *testing.T) {
 	got := Extract(nil, "a.simple.path")
 
@@ -0,0 +1,276 @@
+package jsong
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// field is a struct field resolved with the rules of encoding/json.
+type field struct {
+	name      string
+	tagged    bool  // Whether the name came from the json tag.
+	index     []int // Index sequence for reflect.Value.FieldByIndex.
+	typ       reflect.Type
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool // Whether the value is encoded inside a string.
+}
+
+// typeFields returns the fields of the struct type t in index order
+// including the promoted fields of embedded structs.
+//
+// Fields are resolved exactly like encoding/json: unexported and "-"
+// fields are skipped and of the fields sharing a name the shallowest
+// is used, preferring tagged fields. Fields which remain ambiguous
+// are dropped.
+//
+// See pkg.go.dev/encoding/json#typeFields for details.
+func typeFields(t reflect.Type) []field {
+	var current []field
+	next := []field{{typ: t}}
+
+	// Count of embedded types at the current and next depth.
+	var count map[reflect.Type]int
+	nextCount := map[reflect.Type]int{}
+
+	visited := map[reflect.Type]bool{}
+
+	var fields []field
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				if sf.Anonymous {
+					t := sf.Type
+					if t.Kind() == reflect.Pointer {
+						t = t.Elem()
+					}
+					if !sf.IsExported() && t.Kind() != reflect.Struct {
+						// Ignore embedded fields of unexported non-struct types.
+						continue
+					}
+					// Do not ignore embedded fields of unexported struct types
+					// since they may have exported fields.
+				} else if !sf.IsExported() {
+					continue
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue // Skip no JSON.
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				if !validTagName(name) {
+					name = ""
+				}
+				index := append(slices.Clip(f.index), i)
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				// Only strings, floats, integers, and booleans can be quoted.
+				var quoted bool
+				if tagOption(opts, "string") {
+					switch ft.Kind() {
+					case reflect.Bool,
+						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64,
+						reflect.String:
+						quoted = true
+					}
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					tagged := name != ""
+					if name == "" {
+						name = sf.Name
+					}
+					fields = append(fields, field{
+						name:      name,
+						tagged:    tagged,
+						index:     index,
+						typ:       ft,
+						omitEmpty: tagOption(opts, "omitempty"),
+						omitZero:  tagOption(opts, "omitzero"),
+						quoted:    quoted,
+					})
+					if count[f.typ] > 1 {
+						// The field is ambiguous when its struct is embedded
+						// more than once at the same depth. Add it twice so
+						// dominantField drops it.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				// Record the embedded struct to explore at the next depth.
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, field{name: ft.Name(), index: index, typ: ft})
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(fields, func(a, b field) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.index), len(b.index)); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return +1
+		}
+		return slices.Compare(a.index, b.index)
+	})
+
+	// Drop the fields hidden by the Go rules for embedded fields
+	// except that fields with JSON tags are promoted.
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].name
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != name {
+				break
+			}
+		}
+		if f, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, f)
+		}
+	}
+	fields = out
+	slices.SortFunc(fields, func(a, b field) int { return slices.Compare(a.index, b.index) })
+	return fields
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// cachedTypeFields is like typeFields but uses a cache
+// to avoid resolving the fields of a type more than once.
+// The returned fields must not be modified.
+func cachedTypeFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]field)
+}
+
+// dominantField returns the field hiding the other fields with its name
+// which are sorted by depth and tag. It reports false if the fields are
+// ambiguous.
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+	return fields[0], true
+}
+
+func validTagName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Backslash and quote chars are reserved, but
+			// otherwise any punctuation chars are allowed
+			// in a tag name.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+// tagOption reports whether the comma-separated tag options contain opt.
+func tagOption(opts, opt string) bool {
+	for opts != "" {
+		var name string
+		name, opts, _ = strings.Cut(opts, ",")
+		if name == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue returns the value of the field f of the struct v.
+// It reports false if f is promoted through a nil embedded pointer.
+func fieldValue(v reflect.Value, f *field) (reflect.Value, bool) {
+	for _, i := range f.index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// isEmptyValue reports whether v is empty for omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeFor[isZeroer]()
+
+// isZeroValue reports whether v is zero for omitzero
+// using its IsZero method if it has one.
+func isZeroValue(v reflect.Value) bool {
+	t := v.Type()
+	switch {
+	case t.Kind() == reflect.Interface && t.Implements(isZeroerType):
+		// Avoid panics calling IsZero on a nil interface.
+		return v.IsNil() || v.Interface().(isZeroer).IsZero()
+	case t.Kind() == reflect.Pointer && t.Implements(isZeroerType):
+		// Avoid panics calling IsZero on a nil pointer.
+		return v.IsNil() || v.Interface().(isZeroer).IsZero()
+	case t.Implements(isZeroerType):
+		return v.Interface().(isZeroer).IsZero()
+	case reflect.PointerTo(t).Implements(isZeroerType):
+		if !v.CanAddr() {
+			// Temporarily box v so we can take the address.
+			v2 := reflect.New(t).Elem()
+			v2.Set(v)
+			v = v2
+		}
+		return v.Addr().Interface().(isZeroer).IsZero()
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,276 @@
+package jsong
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fieldMyInt int
+
+type fieldZeroer struct{ V int }
+
+func (z *fieldZeroer) IsZero() bool { return z.V < 0 }
+
+type fieldInner struct {
+	A int
+	B string `json:"b"`
+	C bool   `json:"C,omitempty"`
+}
+
+type fieldOther struct {
+	A int
+	B string
+	D float64
+}
+
+type fieldDeep struct {
+	fieldOther
+	E string
+}
+
+type fieldTagged struct {
+	A int `json:"A"`
+}
+
+type fieldUnexported struct {
+	X int
+	y int
+}
+
+type fieldStruct struct {
+	// Names and tags.
+	Plain     int
+	Named     int    `json:"named"`
+	EmptyName int    `json:",omitempty"`
+	Dash      int    `json:"-"`
+	DashComma int    `json:"-,"`
+	Punct     string `json:"a-b.c$"`
+	private   int
+
+	// Options.
+	OmitEmpty      []int          `json:",omitempty"`
+	OmitEmptyMap   map[string]int `json:",omitempty"`
+	OmitEmptyPtr   *int           `json:",omitempty"`
+	OmitEmptyZero  time.Time      `json:",omitempty"`
+	OmitZero       time.Time      `json:",omitzero"`
+	OmitZeroStruct fieldInner     `json:",omitzero"`
+	OmitZeroMethod fieldZeroer    `json:",omitzero"`
+	OmitZeroPtr    *fieldZeroer   `json:",omitzero"`
+	OmitZeroSlice  []int          `json:",omitzero"`
+	StringInt      int            `json:",string"`
+	StringBool     bool           `json:",string"`
+	StringFloat    float64        `json:",string"`
+	StringString   string         `json:",string"`
+	StringPtr      *int           `json:",string"`
+	StringNilPtr   *int           `json:",string"`
+	StringSlice    []int          `json:",string"`
+	Both           int            `json:",omitempty,string"`
+
+	// Embedding.
+	fieldInner
+	*fieldDeep
+	fieldMyInt
+	Tagged  fieldTagged `json:"tagged"`
+	Nested  fieldInner  `json:"nested"`
+	Ptr     *fieldInner `json:"ptr"`
+	Unexp   fieldUnexported
+	NilDeep *fieldDeep
+}
+
+type fieldAmbiguous struct {
+	fieldInner
+	fieldOther
+}
+
+type fieldTagWins struct {
+	fieldInner
+	fieldTagged
+}
+
+type fieldEmbeddedTwice struct {
+	fieldDeep
+	Deep2 struct{ fieldOther }
+}
+
+type fieldEmbeddedNilPtr struct {
+	*fieldInner
+	E int
+}
+
+type fieldEmbeddedTagged struct {
+	fieldInner `json:"inner"`
+	*fieldOther
+}
+
+type FieldExported struct{ X, Y int }
+
+type fieldEmbeddedExportedPtr struct {
+	*FieldExported
+	Y string
+}
+
+type fieldCycle struct {
+	*fieldCycle
+	A int
+}
+
+type fieldTestCase struct {
+	name  string
+	input any
+}
+
+// newOf returns a pointer to a new zero value of the type of x.
+func newOf(x any) any { return reflect.New(reflect.TypeOf(x)).Interface() }
+
+// nullValues replaces the nil values ValueOf uses for nil pointers,
+// interfaces, slices and maps with the null decoded from encoding/json.
+func nullValues(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return null{}
+	case array:
+		if v == nil {
+			return null{}
+		}
+		for i, e := range v {
+			v[i] = nullValues(e)
+		}
+	case object:
+		if v == nil {
+			return null{}
+		}
+		for k, e := range v {
+			v[k] = nullValues(e)
+		}
+	}
+	return v
+}
+
+func (tc fieldTestCase) runTest(t *testing.T) {
+	t.Helper()
+	want := jsonValueOf(t, tc.input)
+	if diff := cmp.Diff(want, nullValues(ValueOf(tc.input))); diff != "" {
+		t.Errorf("ValueOf(%q): got diff:\n%s", tc.name, diff)
+	}
+
+	// Into matches json.Unmarshal from the same encoding.
+	data, err := json.Marshal(tc.input)
+	if err != nil {
+		t.Fatalf("json.Marshal(%q): got err: %v", tc.name, err)
+	}
+	wantPtr := newOf(tc.input)
+	wantErr := json.Unmarshal(data, wantPtr)
+	gotPtr := newOf(tc.input)
+	gotErr := Into(want, gotPtr)
+	if (gotErr != nil) != (wantErr != nil) {
+		t.Fatalf("Into(%q): got err = %v, want err = %v", tc.name, gotErr, wantErr)
+	}
+	if wantErr != nil {
+		return
+	}
+	if diff := cmp.Diff(wantPtr, gotPtr, cmp.Exporter(func(reflect.Type) bool { return true })); diff != "" {
+		t.Errorf("Into(%q): got diff:\n%s", tc.name, diff)
+	}
+}
+
+func TestStructFields(t *testing.T) {
+	n := 7
+	for _, tc := range []fieldTestCase{{
+		name:  "zero",
+		input: fieldStruct{},
+	}, {
+		name: "full",
+		input: fieldStruct{
+			Plain:          1,
+			Named:          2,
+			EmptyName:      3,
+			Dash:           4,
+			DashComma:      5,
+			Punct:          "p",
+			private:        8,
+			OmitEmpty:      []int{1},
+			OmitEmptyMap:   map[string]int{"a": 1},
+			OmitEmptyPtr:   &n,
+			OmitZero:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			OmitZeroStruct: fieldInner{A: 1},
+			OmitZeroMethod: fieldZeroer{-1},
+			OmitZeroPtr:    &fieldZeroer{-1},
+			OmitZeroSlice:  []int{},
+			StringInt:      -9,
+			StringBool:     true,
+			StringFloat:    1.5e-9,
+			StringString:   "<a\"b>",
+			StringPtr:      &n,
+			StringSlice:    []int{1},
+			Both:           10,
+			fieldInner:     fieldInner{A: 11, B: "b", C: true},
+			fieldDeep:      &fieldDeep{fieldOther: fieldOther{A: 12, B: "c", D: 13}, E: "e"},
+			fieldMyInt:     14,
+			Tagged:         fieldTagged{15},
+			Nested:         fieldInner{A: 16},
+			Ptr:            &fieldInner{B: "ptr"},
+			Unexp:          fieldUnexported{17, 18},
+		},
+	}, {
+		name:  "ambiguous",
+		input: fieldAmbiguous{fieldInner{A: 1, B: "b"}, fieldOther{A: 2, B: "B", D: 3}},
+	}, {
+		name:  "tag wins",
+		input: fieldTagWins{fieldInner{A: 1}, fieldTagged{2}},
+	}, {
+		name:  "embedded twice",
+		input: fieldEmbeddedTwice{fieldDeep{fieldOther{A: 1}, "e"}, struct{ fieldOther }{fieldOther{A: 2}}},
+	}, {
+		name:  "embedded nil pointer",
+		input: fieldEmbeddedNilPtr{E: 1},
+	}, {
+		name:  "embedded tagged",
+		input: fieldEmbeddedTagged{fieldInner{A: 1}, &fieldOther{D: 2}},
+	}, {
+		name:  "embedded exported pointer",
+		input: fieldEmbeddedExportedPtr{&FieldExported{1, 2}, "y"},
+	}, {
+		name:  "embedded cycle",
+		input: fieldCycle{&fieldCycle{A: 1}, 2},
+	}, {
+		name:  "pointer",
+		input: &fieldStruct{Plain: 1, fieldInner: fieldInner{B: "x"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
+
+func TestIntoQuoted(t *testing.T) {
+	var got fieldStruct
+	err := Into(object{"StringInt": str(`"1"`)}, &got)
+	if err == nil || !strings.Contains(err.Error(), `"StringInt"`) {
+		t.Errorf("Into(): got err = %v, want error at \"StringInt\"", err)
+	}
+}
+
+func TestIntoFieldFold(t *testing.T) {
+	var got fieldStruct
+	if err := Into(object{"NAMED": num(1), "plain": num(2)}, &got); err != nil {
+		t.Fatalf("Into(): got err = %v, want err = false", err)
+	}
+	if got.Named != 1 || got.Plain != 2 {
+		t.Errorf("Into(): got Named = %d, Plain = %d, want 1, 2", got.Named, got.Plain)
+	}
+}
+
+func TestTypeFieldsIgnoresUnexported(t *testing.T) {
+	var got []string
+	for _, f := range typeFields(reflect.TypeFor[fieldUnexported]()) {
+		got = append(got, f.name)
+	}
+	if diff := cmp.Diff([]string{"X"}, got); diff != "" {
+		t.Errorf("typeFields(): got diff:\n%s", diff)
+	}
+}
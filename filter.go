@@ -6,11 +6,12 @@ type Filter interface {
 
 // ObjectFieldFilter returns a Filter which returns true
 // only if the object has all the given fields.
+// Values which ValueOf cannot convert are filtered out.
 type ObjectFieldFilter map[string]struct{}
 
 func (f ObjectFieldFilter) Filter(v any) bool {
-	val, ok := ValueOf(v).(object)
-	if !ok {
+	val := valueOf(v)
+	if KindOf(val) != KindObject {
 		return false
 	}
 	for k := range f {
@@ -21,6 +22,8 @@ func (f ObjectFieldFilter) Filter(v any) bool {
 	return true
 }
 
+// GlobFilter returns a Filter which returns true only if
+// the value has a key matching Glob.
 type GlobFilter struct {
 	Glob string
 }
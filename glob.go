@@ -5,27 +5,45 @@ const (
 	doubleStar = "**"
 )
 
+// GlobKey returns the keys of the values in v matching the glob k.
+// Values which ValueOf cannot convert match nothing.
 func GlobKey(v any, k string) []string {
 	var results []string
 	Glob(v, k, func(k string, _ any) { results = append(results, k) })
 	return results
 }
 
+// GlobValues returns the values in v whose keys match the glob k.
+// Values which ValueOf cannot convert match nothing.
 func GlobValues(v any, k string) []any {
 	var results []any
 	Glob(v, k, func(_ string, v any) { results = append(results, v) })
 	return results
 }
 
+// Glob calls visitFn for each value in v whose key matches the glob.
+//
+// A star matches a single path segment and a double star
+// matches any number of path segments. Negative indices like
+// "-1" and slices like "1:3" match the indices they select in
+// arrays. The root value has no key and is never matched.
+// Values which ValueOf cannot convert match nothing.
 func Glob(v any, glob string, visitFn func(k string, v any)) {
-	val, ok := v.(valueInterface)
-	if !ok {
-		val = ValueOf(v).(valueInterface)
+	val := valueOf(v)
+	if val == nil {
+		return
 	}
 	m := Must(CompileKeyMatcher(glob))
+	var lens map[string]int // Array lengths for negative indices and slices.
+	if len(m.rel) > 0 {
+		lens = make(map[string]int)
+	}
 	visit("", val, func(k string, v any) error {
-		if !m.MatchKey(k) {
-			return ErrSkip
+		if lens != nil && KindOf(v) == KindArray {
+			lens[k] = View(v).Len()
+		}
+		if k != "" && m.matchKeyLen(k, lens) {
+			visitFn(k, v)
 		}
 		return nil
 	})
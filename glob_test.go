@@ -129,3 +129,87 @@ func TestGlobKeyMultipleEmbededStar(t *testing.T) {
 		t.Errorf("GlobKey(): got diff:\n%s", diff)
 	}
 }
+
+func TestGlobVisitsMatches(t *testing.T) {
+	v := map[string]any{
+		"a": map[string]any{"b": "x", "c": "y"},
+		"d": "z",
+	}
+
+	got := map[string]any{}
+	Glob(v, "a.*", func(k string, v any) { got[k] = v })
+
+	want := map[string]any{
+		"a.b": str("x"),
+		"a.c": str("y"),
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Glob(): got diff:\n%s", diff)
+	}
+}
+
+func TestGlobSkipsRoot(t *testing.T) {
+	var got []string
+	Glob("x", "**", func(k string, _ any) { got = append(got, k) })
+
+	if len(got) != 0 {
+		t.Errorf("Glob(): got keys %q, want none", got)
+	}
+}
+
+func TestGlobNegativeIndexAndSlice(t *testing.T) {
+	m := map[string]any{
+		"a": []any{
+			[]any{"a", "b", "c"},
+			[]any{"d"},
+		},
+		"b": map[string]any{"-1": 1},
+	}
+
+	for _, tc := range []struct {
+		glob string
+		want []string
+	}{
+		{glob: "a.-1", want: []string{"a.1"}},
+		{glob: "a.*.-1", want: []string{"a.0.2", "a.1.0"}},
+		{glob: "a.0.1:", want: []string{"a.0.1", "a.0.2"}},
+		{glob: "a.:1.:-1", want: []string{"a.0.0", "a.0.1"}},
+		{glob: "a.-3", want: nil},
+		{glob: "b.-1", want: nil},
+		{glob: "**.-1", want: []string{"a.1", "a.0.2", "a.1.0"}},
+	} {
+		got := GlobKey(m, tc.glob)
+
+		lessFunc := func(a, b string) bool { return a < b }
+		if diff := cmp.Diff(tc.want, got, cmpopts.SortSlices(lessFunc)); diff != "" {
+			t.Errorf("Glob(%q): got diff:\n%s", tc.glob, diff)
+		}
+	}
+}
+
+func TestGlobQuotedSegments(t *testing.T) {
+	m := map[string]any{
+		"a.b": map[string]any{"c": 1, "-1": 2},
+		"a":   map[string]any{"b": map[string]any{"c": 3}},
+		"*":   4,
+	}
+
+	for _, tc := range []struct {
+		glob string
+		want []string
+	}{
+		{glob: `"a.b".c`, want: []string{`"a.b".c`}},
+		{glob: `"a.b".*`, want: []string{`"a.b"."-1"`, `"a.b".c`}},
+		{glob: `"a.b"."-1"`, want: []string{`"a.b"."-1"`}},
+		{glob: `"*"`, want: []string{`"*"`}},
+		{glob: `"a".b`, want: []string{"a.b"}},
+	} {
+		got := GlobKey(m, tc.glob)
+
+		lessFunc := func(a, b string) bool { return a < b }
+		if diff := cmp.Diff(tc.want, got, cmpopts.SortSlices(lessFunc)); diff != "" {
+			t.Errorf("Glob(%q): got diff:\n%s", tc.glob, diff)
+		}
+	}
+}
@@ -1,8 +1,16 @@
 module github.com/wenooij/jsong
 
-go 1.21.1
+go 1.24.0
 
 require (
-	github.com/google/go-cmp v0.5.9
+	github.com/google/go-cmp v0.6.0
+	github.com/spf13/cobra v1.10.2
 	golang.org/x/exp v0.0.0-20230905200255-921286631fa9
+	golang.org/x/tools v0.38.0
+)
+
+require (
+	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/mod v0.29.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
 )
@@ -0,0 +1,206 @@
+package jsong
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"slices"
+)
+
+// hamtNode is a node of a hash array mapped trie from string keys
+// to values. Nodes are never modified after they are shared so
+// updates copy the nodes along the path of the key.
+//
+// Each level uses 5 bits of the key hash to select an entry.
+// Keys with the same 64 bit hash are kept in a collision node
+// with a linear list of entries below the last level.
+type hamtNode struct {
+	bitmap  uint32 // Bit i is set if the entry for index i is present.
+	entries []hamtEntry
+}
+
+// hamtEntry is either a key and value or a child node.
+type hamtEntry struct {
+	hash  uint64 // Hash of the key.
+	key   string
+	value any
+	child *hamtNode
+}
+
+const (
+	hamtBits     = 5
+	hamtMask     = 1<<hamtBits - 1
+	hamtMaxShift = 64 // Nodes at this shift are collision nodes.
+)
+
+var hamtSeed = maphash.MakeSeed()
+
+func hamtHash(k string) uint64 { return maphash.String(hamtSeed, k) }
+
+// position returns the bit and entry position for the hash h at shift.
+func (n *hamtNode) position(h uint64, shift uint) (bit uint32, pos int) {
+	bit = 1 << ((h >> shift) & hamtMask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *hamtNode) get(h uint64, shift uint, k string) (any, bool) {
+	for {
+		if shift >= hamtMaxShift {
+			for _, e := range n.entries {
+				if e.key == k {
+					return e.value, true
+				}
+			}
+			return nil, false
+		}
+		bit, pos := n.position(h, shift)
+		if n.bitmap&bit == 0 {
+			return nil, false
+		}
+		e := n.entries[pos]
+		if e.child == nil {
+			return e.value, e.key == k
+		}
+		n, shift = e.child, shift+hamtBits
+	}
+}
+
+// with returns the node with the key k set to v
+// and reports whether k was added.
+func (n *hamtNode) with(h uint64, shift uint, k string, v any) (*hamtNode, bool) {
+	if shift >= hamtMaxShift {
+		i := slices.IndexFunc(n.entries, func(e hamtEntry) bool { return e.key == k })
+		if i < 0 {
+			return &hamtNode{entries: append(slices.Clip(n.entries), hamtEntry{hash: h, key: k, value: v})}, true
+		}
+		return n.replace(i, hamtEntry{hash: h, key: k, value: v}), false
+	}
+	bit, pos := n.position(h, shift)
+	if n.bitmap&bit == 0 {
+		return &hamtNode{
+			bitmap:  n.bitmap | bit,
+			entries: slices.Insert(slices.Clone(n.entries), pos, hamtEntry{hash: h, key: k, value: v}),
+		}, true
+	}
+	e := n.entries[pos]
+	switch {
+	case e.child != nil:
+		child, added := e.child.with(h, shift+hamtBits, k, v)
+		return n.replace(pos, hamtEntry{child: child}), added
+	case e.key == k:
+		return n.replace(pos, hamtEntry{hash: h, key: k, value: v}), false
+	default:
+		// Push the existing entry down to a new child with the key.
+		child, _ := new(hamtNode).with(e.hash, shift+hamtBits, e.key, e.value)
+		child, _ = child.with(h, shift+hamtBits, k, v)
+		return n.replace(pos, hamtEntry{child: child}), true
+	}
+}
+
+// without returns the node without the key k
+// and reports whether k was removed.
+func (n *hamtNode) without(h uint64, shift uint, k string) (*hamtNode, bool) {
+	if shift >= hamtMaxShift {
+		i := slices.IndexFunc(n.entries, func(e hamtEntry) bool { return e.key == k })
+		if i < 0 {
+			return n, false
+		}
+		return &hamtNode{entries: slices.Delete(slices.Clone(n.entries), i, i+1)}, true
+	}
+	bit, pos := n.position(h, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	e := n.entries[pos]
+	if e.child == nil {
+		if e.key != k {
+			return n, false
+		}
+		return &hamtNode{
+			bitmap:  n.bitmap &^ bit,
+			entries: slices.Delete(slices.Clone(n.entries), pos, pos+1),
+		}, true
+	}
+	child, removed := e.child.without(h, shift+hamtBits, k)
+	if !removed {
+		return n, false
+	}
+	switch {
+	case len(child.entries) == 0:
+		return &hamtNode{
+			bitmap:  n.bitmap &^ bit,
+			entries: slices.Delete(slices.Clone(n.entries), pos, pos+1),
+		}, true
+	case len(child.entries) == 1 && child.entries[0].child == nil:
+		// Pull up the last entry of the child.
+		return n.replace(pos, child.entries[0]), true
+	default:
+		return n.replace(pos, hamtEntry{child: child}), true
+	}
+}
+
+// replace returns a copy of n with the entry i replaced by e.
+func (n *hamtNode) replace(i int, e hamtEntry) *hamtNode {
+	entries := slices.Clone(n.entries)
+	entries[i] = e
+	return &hamtNode{bitmap: n.bitmap, entries: entries}
+}
+
+// each calls fn for the entries of n until it returns false.
+func (n *hamtNode) each(fn func(k string, v any) bool) bool {
+	for _, e := range n.entries {
+		if e.child != nil {
+			if !e.child.each(fn) {
+				return false
+			}
+		} else if !fn(e.key, e.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// hamtOf returns a new trie with the entries
+// which must have distinct keys.
+func hamtOf(entries []hamtEntry) *hamtNode {
+	for i := range entries {
+		entries[i].hash = hamtHash(entries[i].key)
+	}
+	return buildHamt(entries, 0)
+}
+
+// buildHamt returns the node at shift for the entries.
+func buildHamt(entries []hamtEntry, shift uint) *hamtNode {
+	if shift >= hamtMaxShift {
+		return &hamtNode{entries: slices.Clone(entries)}
+	}
+	// Sort the entries into buckets by their index at shift.
+	var counts [1 << hamtBits]int
+	n := new(hamtNode)
+	for _, e := range entries {
+		i := (e.hash >> shift) & hamtMask
+		counts[i]++
+		n.bitmap |= 1 << i
+	}
+	var starts [1<<hamtBits + 1]int
+	for i, c := range counts {
+		starts[i+1] = starts[i] + c
+	}
+	sorted := make([]hamtEntry, len(entries))
+	next := starts
+	for _, e := range entries {
+		i := (e.hash >> shift) & hamtMask
+		sorted[next[i]] = e
+		next[i]++
+	}
+	n.entries = make([]hamtEntry, 0, bits.OnesCount32(n.bitmap))
+	for i, c := range counts {
+		switch {
+		case c == 1:
+			n.entries = append(n.entries, sorted[starts[i]])
+		case c > 1:
+			child := buildHamt(sorted[starts[i]:starts[i+1]], shift+hamtBits)
+			n.entries = append(n.entries, hamtEntry{child: child})
+		}
+	}
+	return n
+}
@@ -0,0 +1,475 @@
+// Package gen generates JSONGValue methods which convert
+// struct types to jsong values without reflection.
+//
+// The generated methods give the same jsong values as the reflective
+// conversion by jsong.ValueOf. Fields are resolved like encoding/json
+// at generation time and the methods are regenerated when the
+// struct types change.
+//
+// The methods have pointer receivers. ValueOf uses them for values
+// which are not addressable too, converting an addressable copy,
+// unless the struct has fields with marshal methods only on their
+// pointers which make its conversion depend on addressability.
+package gen
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Header is the first line of generated files.
+const Header = `// Code generated by "jsong gen"; DO NOT EDIT.`
+
+// Generate returns the source of a file for the package in dir declaring
+// JSONGValue methods for the named struct types.
+//
+// The file output, if it is a previously generated file, is ignored
+// when loading the package so that stale methods can be replaced.
+func Generate(dir, output string, typeNames ...string) ([]byte, error) {
+	if len(typeNames) == 0 {
+		return nil, fmt.Errorf("Generate: no types")
+	}
+	pkg, err := load(dir, output)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{}
+	for _, name := range typeNames {
+		obj, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("Generate: type %s not found in package %s", name, pkg.PkgPath)
+		}
+		if err := g.generateType(obj); err != nil {
+			return nil, err
+		}
+	}
+	return g.format(pkg.Name)
+}
+
+// load loads the package in dir ignoring the generated file output.
+func load(dir, output string) (*packages.Package, error) {
+	cfg := &packages.Config{
+		// Type check the dependencies from source rather
+		// than depend on the export data format.
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+	}
+	if output != "" {
+		overlay, err := generatedOverlay(output)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Overlay = overlay
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, fmt.Errorf("Generate: failed to load package: %w", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("Generate: found %d packages in %s", len(pkgs), dir)
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, fmt.Errorf("Generate: failed to load package: %v", pkg.Errors[0])
+	}
+	return pkg, nil
+}
+
+// generatedOverlay returns the overlay replacing the generated
+// file output with its package clause.
+func generatedOverlay(output string) (map[string][]byte, error) {
+	src, err := os.ReadFile(output)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Generate: %w", err)
+	}
+	if !bytes.HasPrefix(src, []byte(Header)) {
+		return nil, nil
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), output, src, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, fmt.Errorf("Generate: %w", err)
+	}
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return nil, fmt.Errorf("Generate: %w", err)
+	}
+	return map[string][]byte{abs: []byte("package " + f.Name.Name + "\n")}, nil
+}
+
+type generator struct {
+	buf           bytes.Buffer
+	importReflect bool
+}
+
+func (g *generator) printf(format string, args ...any) { fmt.Fprintf(&g.buf, format, args...) }
+
+func (g *generator) format(pkgName string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n\npackage %s\n\nimport (\n", Header, pkgName)
+	if g.importReflect {
+		fmt.Fprintf(&b, "\t\"reflect\"\n")
+	}
+	fmt.Fprintf(&b, "\n\t\"github.com/wenooij/jsong\"\n)\n")
+	b.Write(g.buf.Bytes())
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Generate: invalid generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) generateType(obj *types.TypeName) error {
+	named, ok := types.Unalias(obj.Type()).(*types.Named)
+	if !ok || obj.IsAlias() {
+		return fmt.Errorf("Generate: type %s is not a defined type", obj.Name())
+	}
+	if named.TypeParams().Len() > 0 {
+		return fmt.Errorf("Generate: type %s is generic", obj.Name())
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return fmt.Errorf("Generate: type %s is not a struct", obj.Name())
+	}
+	ptr := types.NewPointer(named)
+	if types.Implements(ptr, marshalerIface) || types.Implements(ptr, textMarshalerIface) {
+		// ValueOf would use JSONGValue instead of the marshal method.
+		return fmt.Errorf("Generate: type %s has a marshal method", obj.Name())
+	}
+
+	fields := typeFields(named)
+	g.printf("\n// JSONGValue returns the jsong value of x for jsong.ValueOf.\n")
+	g.printf("func (x *%s) JSONGValue() any {\n", obj.Name())
+	g.printf("s := make(jsong.Struct, 0, %d)\n", len(fields))
+	for _, f := range fields {
+		g.generateField(f)
+	}
+	g.printf("return s\n}\n")
+	return nil
+}
+
+func (g *generator) generateField(f field) {
+	var conds []string
+	sel := "x"
+	for i, v := range f.path {
+		sel += "." + v.Name()
+		if i < len(f.path)-1 {
+			if _, ok := types.Unalias(v.Type()).(*types.Pointer); ok {
+				// Fields promoted through nil pointers are omitted.
+				conds = append(conds, sel+" != nil")
+			}
+		}
+	}
+	t := f.path[len(f.path)-1].Type()
+	if f.omitEmpty {
+		if c := nonEmpty(sel, t); c != "" {
+			conds = append(conds, c)
+		}
+	}
+	if f.omitZero {
+		conds = append(conds, g.nonZero(sel, t))
+	}
+
+	value := sel
+	if needsAddr(t) {
+		value = "&" + sel
+	}
+	quoted := ""
+	if f.quoted {
+		quoted = ", Quoted: true"
+	}
+	stmt := fmt.Sprintf("s = append(s, jsong.StructField{Name: %s, Value: %s%s})\n", strconv.Quote(f.name), value, quoted)
+	if len(conds) == 0 {
+		g.printf("%s", stmt)
+		return
+	}
+	g.printf("if %s {\n%s}\n", strings.Join(conds, " && "), stmt)
+}
+
+// nonEmpty returns the condition that sel of type t is not empty
+// for omitempty or "" if it is never empty.
+func nonEmpty(sel string, t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch info := u.Info(); {
+		case info&types.IsBoolean != 0:
+			return sel
+		case info&types.IsString != 0:
+			return "len(" + sel + ") != 0"
+		case info&(types.IsInteger|types.IsFloat) != 0:
+			return sel + " != 0"
+		}
+	case *types.Array, *types.Slice, *types.Map:
+		return "len(" + sel + ") != 0"
+	case *types.Pointer, *types.Interface:
+		return sel + " != nil"
+	}
+	return ""
+}
+
+// nonZero returns the condition that sel of type t is not zero for omitzero.
+func (g *generator) nonZero(sel string, t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Interface, *types.Pointer:
+		if types.Implements(t, isZeroerIface) {
+			// Avoid panics calling IsZero on nil.
+			return sel + " != nil && !" + sel + ".IsZero()"
+		}
+	default:
+		if types.Implements(t, isZeroerIface) || types.Implements(types.NewPointer(t), isZeroerIface) {
+			return "!" + sel + ".IsZero()"
+		}
+		if _, ok := u.(*types.Basic); ok {
+			if c := nonEmpty(sel, t); c != "" {
+				return c
+			}
+		}
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		return sel + " != nil"
+	}
+	g.importReflect = true
+	return "!reflect.ValueOf(" + sel + ").IsZero()"
+}
+
+// needsAddr reports whether fields of type t are passed by pointer
+// so that ValueOf uses the pointer methods of the addressable field
+// and of the fields and elements it contains.
+func needsAddr(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return false
+	case *types.Struct, *types.Array:
+		return true
+	}
+	ptr := types.NewPointer(t)
+	return types.Implements(ptr, valuerIface) ||
+		types.Implements(ptr, marshalerIface) ||
+		types.Implements(ptr, textMarshalerIface)
+}
+
+var (
+	valuerIface        = newInterface("JSONGValue", types.Universe.Lookup("any").Type())
+	marshalerIface     = newInterface("MarshalJSON", types.NewSlice(types.Typ[types.Byte]), types.Universe.Lookup("error").Type())
+	textMarshalerIface = newInterface("MarshalText", types.NewSlice(types.Typ[types.Byte]), types.Universe.Lookup("error").Type())
+	isZeroerIface      = newInterface("IsZero", types.Typ[types.Bool])
+)
+
+// newInterface returns the interface with a single method
+// without parameters which returns results.
+func newInterface(name string, results ...types.Type) *types.Interface {
+	vars := make([]*types.Var, len(results))
+	for i, t := range results {
+		vars[i] = types.NewParam(token.NoPos, nil, "", t)
+	}
+	sig := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(vars...), false)
+	return types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, nil, name, sig)}, nil).Complete()
+}
+
+// field is a struct field resolved with the rules of encoding/json.
+type field struct {
+	name      string
+	tagged    bool         // Whether the name came from the json tag.
+	index     []int        // Index sequence of the field.
+	path      []*types.Var // Fields selected by index.
+	typ       types.Type
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool // Whether the value is encoded inside a string.
+}
+
+// typeFields returns the fields of the struct type t in index order
+// including the promoted fields of embedded structs.
+//
+// It is the same as the typeFields of jsong for go/types.
+func typeFields(t types.Type) []field {
+	var current []field
+	next := []field{{typ: t}}
+
+	// Count of embedded types at the current and next depth.
+	var count map[string]int
+	nextCount := map[string]int{}
+
+	visited := map[string]bool{}
+
+	var fields []field
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[string]int{}
+
+		for _, f := range current {
+			key := types.TypeString(f.typ, nil)
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			st := f.typ.Underlying().(*types.Struct)
+			for i := 0; i < st.NumFields(); i++ {
+				sf := st.Field(i)
+				if sf.Embedded() {
+					t := sf.Type()
+					if p, ok := types.Unalias(t).(*types.Pointer); ok {
+						t = p.Elem()
+					}
+					if !sf.Exported() && !isStruct(t) {
+						// Ignore embedded fields of unexported non-struct types.
+						continue
+					}
+					// Do not ignore embedded fields of unexported struct types
+					// since they may have exported fields.
+				} else if !sf.Exported() {
+					continue
+				}
+				tag := reflect.StructTag(st.Tag(i)).Get("json")
+				if tag == "-" {
+					continue // Skip no JSON.
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				if !validTagName(name) {
+					name = ""
+				}
+				index := append(slices.Clip(f.index), i)
+				path := append(slices.Clip(f.path), sf)
+
+				ft := sf.Type()
+				if p, ok := types.Unalias(ft).(*types.Pointer); ok {
+					ft = p.Elem()
+				}
+
+				// Only strings, floats, integers, and booleans can be quoted.
+				var quoted bool
+				if tagOption(opts, "string") {
+					if b, ok := ft.Underlying().(*types.Basic); ok {
+						quoted = b.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) != 0
+					}
+				}
+
+				if name != "" || !sf.Embedded() || !isStruct(ft) {
+					tagged := name != ""
+					if name == "" {
+						name = sf.Name()
+					}
+					fields = append(fields, field{
+						name:      name,
+						tagged:    tagged,
+						index:     index,
+						path:      path,
+						typ:       ft,
+						omitEmpty: tagOption(opts, "omitempty"),
+						omitZero:  tagOption(opts, "omitzero"),
+						quoted:    quoted,
+					})
+					if count[key] > 1 {
+						// The field is ambiguous when its struct is embedded
+						// more than once at the same depth. Add it twice so
+						// dominantField drops it.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				// Record the embedded struct to explore at the next depth.
+				ftKey := types.TypeString(ft, nil)
+				nextCount[ftKey]++
+				if nextCount[ftKey] == 1 {
+					next = append(next, field{index: index, path: path, typ: ft})
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(fields, func(a, b field) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.index), len(b.index)); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return +1
+		}
+		return slices.Compare(a.index, b.index)
+	})
+
+	// Drop the fields hidden by the Go rules for embedded fields
+	// except that fields with JSON tags are promoted.
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].name
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != name {
+				break
+			}
+		}
+		if f, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, f)
+		}
+	}
+	fields = out
+	slices.SortFunc(fields, func(a, b field) int { return slices.Compare(a.index, b.index) })
+	return fields
+}
+
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// dominantField returns the field hiding the other fields with its name
+// which are sorted by depth and tag. It reports false if the fields are
+// ambiguous.
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+	return fields[0], true
+}
+
+func validTagName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Backslash and quote chars are reserved, but
+			// otherwise any punctuation chars are allowed
+			// in a tag name.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+// tagOption reports whether the comma-separated tag options contain opt.
+func tagOption(opts, opt string) bool {
+	for opts != "" {
+		var name string
+		name, opts, _ = strings.Cut(opts, ",")
+		if name == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package gen
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wenooij/jsong"
+	"github.com/wenooij/jsong/internal/gen/gentest"
+)
+
+var update = flag.Bool("update", false, "update the generated golden file")
+
+const goldenFile = "gentest/gentest_jsong.go"
+
+func TestGenerateGolden(t *testing.T) {
+	got, err := Generate("gentest", goldenFile, "Event", "User", "Node", "Embedding", "Options")
+	if err != nil {
+		t.Fatalf("Generate(): got err = %v, want err = false", err)
+	}
+	if *update {
+		if err := os.WriteFile(goldenFile, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("Generate(): got diff:\n%s", diff)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, name := range []string{"Missing", "Kind", "Upper"} {
+		if _, err := Generate("gentest", goldenFile, name); err == nil {
+			t.Errorf("Generate(%q): got err = false, want err = true", name)
+		}
+	}
+}
+
+// Types without the generated methods for the reflective conversion.
+type (
+	plainEvent     gentest.Event
+	plainUser      gentest.User
+	plainNode      gentest.Node
+	plainEmbedding gentest.Embedding
+	plainOptions   gentest.Options
+)
+
+// plain returns the pointer v converted to a type without JSONGValue.
+func plain(v any) any {
+	switch v := v.(type) {
+	case *gentest.Event:
+		return (*plainEvent)(v)
+	case *gentest.User:
+		return (*plainUser)(v)
+	case *gentest.Node:
+		return (*plainNode)(v)
+	case *gentest.Embedding:
+		return (*plainEmbedding)(v)
+	case *gentest.Options:
+		return (*plainOptions)(v)
+	default:
+		panic("unexpected type")
+	}
+}
+
+func newValues() []any {
+	ratio := float32(0.25)
+	cycle := &gentest.Node{Value: 1}
+	cycle.Children = []*gentest.Node{{Value: 2}, {Value: 3, Next: &gentest.Node{}}}
+	negZero := math.Copysign(0, -1)
+	zeroTime := time.Time{}
+	return []any{
+		&gentest.Event{},
+		&gentest.Event{
+			ID:        "4f0c8e2a",
+			Type:      "click",
+			Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+			Updated:   time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+			User:      &gentest.User{Name: "gopher", Admin: true},
+			Owner:     gentest.User{Email: "owner@example.com"},
+			Users:     []gentest.User{{Name: "a"}, {Name: "b"}},
+			Tags:      []string{"a", "b"},
+			Labels:    map[string]string{"env": "prod"},
+			Count:     3,
+			Score:     negZero,
+			Ratio:     &ratio,
+			Extra:     map[string]any{"a": []any{1, "b", nil}},
+			Number:    "12345678901234567890",
+			Raw:       json.RawMessage(`{"b":1,"a":[true]}`),
+			Upper:     "up",
+			Uppers:    [2]gentest.Upper{"x", "y"},
+			Internal:  "internal",
+			Dash:      "dash",
+			Untagged:  true,
+		},
+		cycle,
+		&gentest.Embedding{Base: gentest.Base{ID: 1, Name: "base"}, ID: "id"},
+		&gentest.Embedding{Meta: &gentest.Meta{Name: "meta", Version: 2}},
+		&gentest.Options{},
+		&gentest.Options{
+			Bool:       true,
+			Int:        -1,
+			Uint:       1,
+			Float:      negZero,
+			String:     "s",
+			Slice:      []int{},
+			Map:        map[int]int{1: 2},
+			Pointer:    new(int),
+			Interface:  false,
+			ZeroFloat:  float32(negZero),
+			ZeroSlice:  []int{},
+			ZeroArray:  [1]float64{negZero},
+			ZeroTime:   &zeroTime,
+			ZeroUser:   gentest.User{Admin: true},
+			Quoted:     `"q"`,
+			QuotedB:    true,
+			Func:       func() {},
+			Complex:    1,
+			Fields:     jsong.Struct{{Name: "b", Value: 1}, {Name: "a", Value: "2", Quoted: true}},
+			ZeroStruct: struct{ A []int }{A: []int{}},
+		},
+	}
+}
+
+func TestGeneratedValueOf(t *testing.T) {
+	for _, opts := range []jsong.ValueOptions{
+		{Unsupported: jsong.UnsupportedNull},
+		{Unsupported: jsong.UnsupportedOmit},
+		{Unsupported: jsong.UnsupportedNull, ExactNumbers: true},
+		{Unsupported: jsong.UnsupportedNull, OrderedObjects: true},
+	} {
+		for _, v := range newValues() {
+			if _, ok := v.(jsong.Valuer); !ok {
+				t.Fatalf("%T does not implement jsong.Valuer", v)
+			}
+			want, err := opts.ValueOfErr(plain(v))
+			if err != nil {
+				t.Fatalf("ValueOfErr(%T): got err = %v, want err = false", v, err)
+			}
+			got, err := opts.ValueOfErr(v)
+			if err != nil {
+				t.Fatalf("ValueOfErr(%T): got err = %v, want err = false", v, err)
+			}
+			if diff := cmp.Diff(want, got, cmp.Exporter(func(reflect.Type) bool { return true })); diff != "" {
+				t.Errorf("ValueOfErr(%T, %+v): got diff:\n%s", v, opts, diff)
+			}
+			var wantJSON, gotJSON []byte
+			if wantJSON, err = json.Marshal(want); err != nil {
+				t.Fatal(err)
+			}
+			if gotJSON, err = json.Marshal(got); err != nil {
+				t.Fatal(err)
+			}
+			if string(wantJSON) != string(gotJSON) {
+				t.Errorf("ValueOfErr(%T, %+v): got JSON %s, want %s", v, opts, gotJSON, wantJSON)
+			}
+		}
+	}
+}
+
+func TestGeneratedValueOfValues(t *testing.T) {
+	opts := jsong.ValueOptions{Unsupported: jsong.UnsupportedNull}
+	for _, v := range newValues() {
+		// Values which are not addressable.
+		value := reflect.ValueOf(v).Elem().Interface()
+		plainValue := reflect.ValueOf(plain(v)).Elem().Interface()
+
+		want, err := opts.ValueOfErr(plainValue)
+		if err != nil {
+			t.Fatalf("ValueOfErr(%T): got err = %v, want err = false", plainValue, err)
+		}
+		got, err := opts.ValueOfErr(value)
+		if err != nil {
+			t.Fatalf("ValueOfErr(%T): got err = %v, want err = false", value, err)
+		}
+		if diff := cmp.Diff(want, got, cmp.Exporter(func(reflect.Type) bool { return true })); diff != "" {
+			t.Errorf("ValueOfErr(%T): got diff:\n%s", value, diff)
+		}
+	}
+}
+
+// userProbe has the generated method of User
+// which omits Probe unlike the reflective conversion.
+type userProbe struct {
+	gentest.User
+	Probe bool `json:"probe"`
+}
+
+func TestGeneratedValueOfCallsMethodOnValues(t *testing.T) {
+	v := userProbe{User: gentest.User{Name: "gopher"}, Probe: true}
+
+	got := jsong.ValueOf(v)
+
+	if jsong.Extract(got, "probe") != nil {
+		t.Errorf("ValueOf(): got %v, want the value of the generated method", got)
+	}
+	if name, _ := jsong.String(jsong.Extract(got, "name")); name != "gopher" {
+		t.Errorf("ValueOf(): got name %q, want gopher", name)
+	}
+}
+
+func TestGeneratedValueOfErrors(t *testing.T) {
+	cycle := &gentest.Node{}
+	cycle.Next = cycle
+	for _, tc := range []struct {
+		v    any
+		want error
+	}{
+		{v: cycle, want: jsong.ErrCycle},
+		{v: &gentest.Options{Func: func() {}}, want: jsong.ErrUnsupportedType},
+	} {
+		_, wantErr := jsong.ValueOfErr(plain(tc.v))
+		_, err := jsong.ValueOfErr(tc.v)
+		if !errors.Is(err, tc.want) || !errors.Is(wantErr, tc.want) {
+			t.Fatalf("ValueOfErr(%T): got err = %v, want %v", tc.v, err, tc.want)
+		}
+		var gotErr, reflectErr *jsong.UnsupportedValueError
+		if errors.As(err, &gotErr) && errors.As(wantErr, &reflectErr) && gotErr.Path != reflectErr.Path {
+			t.Errorf("ValueOfErr(%T): got path %q, want %q", tc.v, gotErr.Path, reflectErr.Path)
+		}
+	}
+}
+
+func BenchmarkGeneratedValueOf(b *testing.B) {
+	v := &gentest.User{Name: "gopher", Email: "gopher@example.com", Admin: true}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		jsong.ValueOf(v)
+	}
+}
+
+func BenchmarkGeneratedValueOfReflect(b *testing.B) {
+	v := plain(&gentest.User{Name: "gopher", Email: "gopher@example.com", Admin: true})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		jsong.ValueOf(v)
+	}
+}
@@ -0,0 +1,106 @@
+// Package gentest declares struct types with generated JSONGValue methods
+// to test that they give the same values as the reflective conversion.
+package gentest
+
+//go:generate go run ../../../jsong gen --type Event,User,Node,Embedding,Options --output gentest_jsong.go
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/wenooij/jsong"
+)
+
+type Event struct {
+	ID        string            `json:"id"`
+	Type      Kind              `json:"type"`
+	Timestamp time.Time         `json:"timestamp"`
+	Updated   time.Time         `json:"updated,omitzero"`
+	User      *User             `json:"user,omitempty"`
+	Owner     User              `json:"owner"`
+	Users     []User            `json:"users,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Count     int               `json:"count,string"`
+	Score     float64           `json:"score,omitempty"`
+	Ratio     *float32          `json:"ratio,string"`
+	Extra     any               `json:"extra,omitempty"`
+	Number    json.Number       `json:"number,omitempty"`
+	Raw       json.RawMessage   `json:"raw,omitempty"`
+	Upper     Upper             `json:"upper"`
+	Uppers    [2]Upper          `json:"uppers"`
+	Internal  string            `json:"-"`
+	Dash      string            `json:"-,"`
+	Untagged  bool
+	hidden    int
+}
+
+type Kind string
+
+// Upper has a pointer marshal method used for addressable values.
+type Upper string
+
+func (u *Upper) MarshalText() ([]byte, error) { return []byte(strings.ToUpper(string(*u))), nil }
+
+type User struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Admin bool   `json:"admin,omitempty"`
+}
+
+type Node struct {
+	Value    int     `json:"value"`
+	Next     *Node   `json:"next,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+type Base struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Meta struct {
+	Name    string `json:"name"` // Ambiguous with Base.Name.
+	Version int    `json:"version"`
+}
+
+type inner struct {
+	Secret string `json:"secret"`
+}
+
+type Embedding struct {
+	Base
+	*Meta
+	inner
+	ID string `json:"id"` // Hides Base.ID.
+}
+
+type Options struct {
+	Bool       bool            `json:",omitempty"`
+	Int        int8            `json:",omitempty"`
+	Uint       uintptr         `json:",omitempty"`
+	Float      float64         `json:",omitempty"`
+	String     string          `json:",omitempty"`
+	Array      [0]int          `json:",omitempty"`
+	Slice      []int           `json:",omitempty"`
+	Map        map[int]int     `json:",omitempty"`
+	Pointer    *int            `json:",omitempty"`
+	Interface  any             `json:",omitempty"`
+	Struct     struct{ A int } `json:",omitempty"`
+	ZeroBool   bool            `json:",omitzero"`
+	ZeroFloat  float32         `json:",omitzero"`
+	ZeroSlice  []int           `json:",omitzero"`
+	ZeroStruct struct {
+		A []int
+	} `json:",omitzero"`
+	ZeroArray [1]float64   `json:",omitzero"`
+	ZeroTime  *time.Time   `json:",omitzero"`
+	ZeroUser  User         `json:",omitzero"`
+	Quoted    string       `json:",string"`
+	QuotedB   bool         `json:",string"`
+	Func      func()       `json:",omitempty"`
+	Chan      chan int     `json:",omitempty"`
+	Complex   complex64    `json:",omitempty"`
+	Fields    jsong.Struct `json:",omitempty"`
+}
@@ -0,0 +1,155 @@
+// Code generated by "jsong gen"; DO NOT EDIT.
+
+package gentest
+
+import (
+	"reflect"
+
+	"github.com/wenooij/jsong"
+)
+
+// JSONGValue returns the jsong value of x for jsong.ValueOf.
+func (x *Event) JSONGValue() any {
+	s := make(jsong.Struct, 0, 19)
+	s = append(s, jsong.StructField{Name: "id", Value: x.ID})
+	s = append(s, jsong.StructField{Name: "type", Value: x.Type})
+	s = append(s, jsong.StructField{Name: "timestamp", Value: &x.Timestamp})
+	if !x.Updated.IsZero() {
+		s = append(s, jsong.StructField{Name: "updated", Value: &x.Updated})
+	}
+	if x.User != nil {
+		s = append(s, jsong.StructField{Name: "user", Value: x.User})
+	}
+	s = append(s, jsong.StructField{Name: "owner", Value: &x.Owner})
+	if len(x.Users) != 0 {
+		s = append(s, jsong.StructField{Name: "users", Value: x.Users})
+	}
+	if len(x.Tags) != 0 {
+		s = append(s, jsong.StructField{Name: "tags", Value: x.Tags})
+	}
+	if len(x.Labels) != 0 {
+		s = append(s, jsong.StructField{Name: "labels", Value: x.Labels})
+	}
+	s = append(s, jsong.StructField{Name: "count", Value: x.Count, Quoted: true})
+	if x.Score != 0 {
+		s = append(s, jsong.StructField{Name: "score", Value: x.Score})
+	}
+	s = append(s, jsong.StructField{Name: "ratio", Value: x.Ratio, Quoted: true})
+	if x.Extra != nil {
+		s = append(s, jsong.StructField{Name: "extra", Value: x.Extra})
+	}
+	if len(x.Number) != 0 {
+		s = append(s, jsong.StructField{Name: "number", Value: x.Number})
+	}
+	if len(x.Raw) != 0 {
+		s = append(s, jsong.StructField{Name: "raw", Value: &x.Raw})
+	}
+	s = append(s, jsong.StructField{Name: "upper", Value: &x.Upper})
+	s = append(s, jsong.StructField{Name: "uppers", Value: &x.Uppers})
+	s = append(s, jsong.StructField{Name: "-", Value: x.Dash})
+	s = append(s, jsong.StructField{Name: "Untagged", Value: x.Untagged})
+	return s
+}
+
+// JSONGValue returns the jsong value of x for jsong.ValueOf.
+func (x *User) JSONGValue() any {
+	s := make(jsong.Struct, 0, 3)
+	s = append(s, jsong.StructField{Name: "name", Value: x.Name})
+	if len(x.Email) != 0 {
+		s = append(s, jsong.StructField{Name: "email", Value: x.Email})
+	}
+	if x.Admin {
+		s = append(s, jsong.StructField{Name: "admin", Value: x.Admin})
+	}
+	return s
+}
+
+// JSONGValue returns the jsong value of x for jsong.ValueOf.
+func (x *Node) JSONGValue() any {
+	s := make(jsong.Struct, 0, 3)
+	s = append(s, jsong.StructField{Name: "value", Value: x.Value})
+	if x.Next != nil {
+		s = append(s, jsong.StructField{Name: "next", Value: x.Next})
+	}
+	if len(x.Children) != 0 {
+		s = append(s, jsong.StructField{Name: "children", Value: x.Children})
+	}
+	return s
+}
+
+// JSONGValue returns the jsong value of x for jsong.ValueOf.
+func (x *Embedding) JSONGValue() any {
+	s := make(jsong.Struct, 0, 3)
+	if x.Meta != nil {
+		s = append(s, jsong.StructField{Name: "version", Value: x.Meta.Version})
+	}
+	s = append(s, jsong.StructField{Name: "secret", Value: x.inner.Secret})
+	s = append(s, jsong.StructField{Name: "id", Value: x.ID})
+	return s
+}
+
+// JSONGValue returns the jsong value of x for jsong.ValueOf.
+func (x *Options) JSONGValue() any {
+	s := make(jsong.Struct, 0, 24)
+	if x.Bool {
+		s = append(s, jsong.StructField{Name: "Bool", Value: x.Bool})
+	}
+	if x.Int != 0 {
+		s = append(s, jsong.StructField{Name: "Int", Value: x.Int})
+	}
+	if x.Uint != 0 {
+		s = append(s, jsong.StructField{Name: "Uint", Value: x.Uint})
+	}
+	if x.Float != 0 {
+		s = append(s, jsong.StructField{Name: "Float", Value: x.Float})
+	}
+	if len(x.String) != 0 {
+		s = append(s, jsong.StructField{Name: "String", Value: x.String})
+	}
+	if len(x.Array) != 0 {
+		s = append(s, jsong.StructField{Name: "Array", Value: &x.Array})
+	}
+	if len(x.Slice) != 0 {
+		s = append(s, jsong.StructField{Name: "Slice", Value: x.Slice})
+	}
+	if len(x.Map) != 0 {
+		s = append(s, jsong.StructField{Name: "Map", Value: x.Map})
+	}
+	if x.Pointer != nil {
+		s = append(s, jsong.StructField{Name: "Pointer", Value: x.Pointer})
+	}
+	if x.Interface != nil {
+		s = append(s, jsong.StructField{Name: "Interface", Value: x.Interface})
+	}
+	s = append(s, jsong.StructField{Name: "Struct", Value: &x.Struct})
+	if x.ZeroBool {
+		s = append(s, jsong.StructField{Name: "ZeroBool", Value: x.ZeroBool})
+	}
+	if x.ZeroFloat != 0 {
+		s = append(s, jsong.StructField{Name: "ZeroFloat", Value: x.ZeroFloat})
+	}
+	if x.ZeroSlice != nil {
+		s = append(s, jsong.StructField{Name: "ZeroSlice", Value: x.ZeroSlice})
+	}
+	if !reflect.ValueOf(x.ZeroStruct).IsZero() {
+		s = append(s, jsong.StructField{Name: "ZeroStruct", Value: &x.ZeroStruct})
+	}
+	if !reflect.ValueOf(x.ZeroArray).IsZero() {
+		s = append(s, jsong.StructField{Name: "ZeroArray", Value: &x.ZeroArray})
+	}
+	if x.ZeroTime != nil && !x.ZeroTime.IsZero() {
+		s = append(s, jsong.StructField{Name: "ZeroTime", Value: x.ZeroTime})
+	}
+	if !reflect.ValueOf(x.ZeroUser).IsZero() {
+		s = append(s, jsong.StructField{Name: "ZeroUser", Value: &x.ZeroUser})
+	}
+	s = append(s, jsong.StructField{Name: "Quoted", Value: x.Quoted, Quoted: true})
+	s = append(s, jsong.StructField{Name: "QuotedB", Value: x.QuotedB, Quoted: true})
+	s = append(s, jsong.StructField{Name: "Func", Value: x.Func})
+	s = append(s, jsong.StructField{Name: "Chan", Value: x.Chan})
+	s = append(s, jsong.StructField{Name: "Complex", Value: x.Complex})
+	if len(x.Fields) != 0 {
+		s = append(s, jsong.StructField{Name: "Fields", Value: x.Fields})
+	}
+	return s
+}
@@ -0,0 +1,428 @@
+package jsong
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// IntoTypeError describes a value which cannot be stored
+// into a Go value of a specific type.
+type IntoTypeError struct {
+	Value string       // Description of the value: "bool", "array", "number -5".
+	Type  reflect.Type // Type of the Go value it could not be stored into.
+	Path  string       // Path of the value as used by Extract.
+}
+
+func (e *IntoTypeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("Into: cannot store %s into Go value of type %s", e.Value, e.Type)
+	}
+	return fmt.Sprintf("Into: cannot store %s at %q into Go value of type %s", e.Value, e.Path, e.Type)
+}
+
+// Into stores the value v into the Go value pointed to by dst.
+//
+// It performs an operation similar to, but more
+// efficient than:
+//
+//	data, _ := json.Marshal(v)
+//	json.Unmarshal(data, dst)
+//
+// Struct fields are resolved using the same rules as ValueOf
+// preferring an exact match of the key but accepting a case-insensitive
+// match. Keys without a field are ignored. Null sets pointers, maps,
+// slices and interfaces to nil and leaves other values unchanged.
+// Interfaces receive the same Go values as json.Unmarshal except that
+// exact numbers are stored as json.Number. Values other than null are
+// stored using json.Unmarshaler and encoding.TextUnmarshaler methods.
+//
+// Into stops at the first value which does not fit its destination
+// and returns an *IntoTypeError with its path.
+func Into(v any, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("Into: non-nil pointer required, got %T", dst)
+	}
+	x, err := ValueOfErr(v)
+	if err != nil {
+		return err
+	}
+	val, _ := x.(valueInterface)
+	return new(intoState).into(val, rv.Elem())
+}
+
+type intoState struct {
+	path []any
+}
+
+func (s *intoState) typeError(v valueInterface, t reflect.Type) error {
+	desc := "null"
+	switch v := materialize(v).(type) {
+	case boolean:
+		desc = "bool"
+	case num, decimal:
+		n, _ := numberText(v)
+		desc = "number " + n
+	case str:
+		desc = "string"
+	case array:
+		desc = "array"
+	case object, *orderedObject:
+		desc = "object"
+	}
+	return &IntoTypeError{Value: desc, Type: t, Path: JoinKey("", s.path...)}
+}
+
+func (s *intoState) into(v valueInterface, dst reflect.Value) error {
+	switch v.(type) {
+	case nil, null:
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			dst.SetZero()
+		}
+		return nil
+	}
+	v = materialize(v)
+	if u, ok := unmarshalerOf(dst); ok {
+		return s.intoUnmarshaler(v, dst.Type(), u)
+	}
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return s.into(v, dst.Elem())
+	}
+	if dst.Kind() == reflect.Interface {
+		if dst.NumMethod() > 0 {
+			return s.typeError(v, dst.Type())
+		}
+		dst.Set(reflect.ValueOf(plainValue(v)))
+		return nil
+	}
+	switch v := v.(type) {
+	case boolean:
+		if dst.Kind() != reflect.Bool {
+			return s.typeError(v, dst.Type())
+		}
+		dst.SetBool(bool(v))
+		return nil
+	case num, decimal:
+		return s.intoNumber(v, dst)
+	case str:
+		return s.intoString(v, dst)
+	case array:
+		return s.intoArray(v, dst)
+	case object, *orderedObject:
+		return s.intoObject(v, dst)
+	default:
+		return fmt.Errorf("Into: unexpected value type %T", v)
+	}
+}
+
+var (
+	unmarshalerType     = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// unmarshalerOf returns the json.Unmarshaler or encoding.TextUnmarshaler
+// of the addressable value v.
+func unmarshalerOf(v reflect.Value) (any, bool) {
+	if v.Kind() == reflect.Pointer || !v.CanAddr() || !v.Addr().CanInterface() {
+		return nil, false
+	}
+	pt := reflect.PointerTo(v.Type())
+	if pt.Implements(unmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+func (s *intoState) intoUnmarshaler(v valueInterface, t reflect.Type, u any) error {
+	if u, ok := u.(json.Unmarshaler); ok {
+		b, err := new(Encoder).appendValue(nil, v, 0)
+		if err == nil {
+			err = u.UnmarshalJSON(b)
+		}
+		if err != nil {
+			return fmt.Errorf("Into: error calling UnmarshalJSON for type %s at %q: %w", t, JoinKey("", s.path...), err)
+		}
+		return nil
+	}
+	text, ok := v.(str)
+	if !ok {
+		return s.typeError(v, t)
+	}
+	if err := u.(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+		return fmt.Errorf("Into: error calling UnmarshalText for type %s at %q: %w", t, JoinKey("", s.path...), err)
+	}
+	return nil
+}
+
+func (s *intoState) intoNumber(v valueInterface, dst reflect.Value) error {
+	if dst.Type() == numberType {
+		n, _ := numberText(v)
+		dst.SetString(n)
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		i, ok := int64Value(v)
+		if !ok || dst.OverflowInt(i) {
+			return s.typeError(v, dst.Type())
+		}
+		dst.SetInt(i)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		u, ok := uint64Value(v)
+		if !ok || dst.OverflowUint(u) {
+			return s.typeError(v, dst.Type())
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, _ := Float64(v)
+		if dst.OverflowFloat(f) {
+			return s.typeError(v, dst.Type())
+		}
+		dst.SetFloat(f)
+	default:
+		return s.typeError(v, dst.Type())
+	}
+	return nil
+}
+
+func (s *intoState) intoString(v str, dst reflect.Value) error {
+	switch {
+	case dst.Kind() == reflect.String:
+		dst.SetString(string(v))
+	case dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8:
+		// Special case for []byte to match ValueOf.
+		dst.SetBytes([]byte(v))
+	default:
+		return s.typeError(v, dst.Type())
+	}
+	return nil
+}
+
+func (s *intoState) intoArray(v array, dst reflect.Value) error {
+	switch dst.Kind() {
+	case reflect.Slice:
+		if dst.IsNil() || dst.Cap() < len(v) {
+			dst.Set(reflect.MakeSlice(dst.Type(), len(v), len(v)))
+		} else {
+			dst.SetLen(len(v))
+		}
+	case reflect.Array:
+		for i := len(v); i < dst.Len(); i++ {
+			dst.Index(i).SetZero()
+		}
+	default:
+		return s.typeError(v, dst.Type())
+	}
+	for i, e := range v {
+		if i >= dst.Len() {
+			break // Extra elements are ignored for arrays.
+		}
+		s.path = append(s.path, int64(i))
+		if err := s.into(asValue(e), dst.Index(i)); err != nil {
+			return err
+		}
+		s.path = s.path[:len(s.path)-1]
+	}
+	return nil
+}
+
+func (s *intoState) intoObject(v valueInterface, dst reflect.Value) error {
+	switch dst.Kind() {
+	case reflect.Map:
+		return s.intoMap(v, dst)
+	case reflect.Struct:
+		return s.intoStruct(v, dst)
+	default:
+		return s.typeError(v, dst.Type())
+	}
+}
+
+func (s *intoState) intoMap(v valueInterface, dst reflect.Value) error {
+	t := dst.Type()
+	switch t.Key().Kind() {
+	case reflect.String,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+	default:
+		if !reflect.PointerTo(t.Key()).Implements(textUnmarshalerType) {
+			return s.typeError(v, t)
+		}
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMap(t))
+	}
+	var err error
+	v.Each(func(k, e any) bool {
+		s.path = append(s.path, k)
+		key := reflect.New(t.Key()).Elem()
+		if err = s.intoMapKey(k.(string), key); err != nil {
+			return false
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if err = s.into(asValue(e), elem); err != nil {
+			return false
+		}
+		dst.SetMapIndex(key, elem)
+		s.path = s.path[:len(s.path)-1]
+		return true
+	})
+	return err
+}
+
+func (s *intoState) intoMapKey(k string, key reflect.Value) error {
+	if u, ok := unmarshalerOf(key); ok {
+		if u, ok := u.(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(k)); err != nil {
+				return fmt.Errorf("Into: error calling UnmarshalText for type %s at %q: %w", key.Type(), JoinKey("", s.path...), err)
+			}
+			return nil
+		}
+	}
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(k)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		i, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || key.OverflowInt(i) {
+			return s.typeError(str(k), key.Type())
+		}
+		key.SetInt(i)
+	default:
+		u, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || key.OverflowUint(u) {
+			return s.typeError(str(k), key.Type())
+		}
+		key.SetUint(u)
+	}
+	return nil
+}
+
+func (s *intoState) intoStruct(v valueInterface, dst reflect.Value) error {
+	fields := cachedTypeFields(dst.Type())
+	var err error
+	v.Each(func(k, e any) bool {
+		f := lookupField(fields, k.(string))
+		if f == nil {
+			return true // Keys without a field are ignored.
+		}
+		s.path = append(s.path, k)
+		var fv reflect.Value
+		if fv, err = s.fieldByIndex(dst, f); err != nil {
+			return false
+		}
+		if f.quoted {
+			err = s.intoQuoted(asValue(e), fv)
+		} else {
+			err = s.into(asValue(e), fv)
+		}
+		if err != nil {
+			return false
+		}
+		s.path = s.path[:len(s.path)-1]
+		return true
+	})
+	return err
+}
+
+// lookupField returns the field for the object key k
+// preferring an exact match over a case-insensitive one.
+func lookupField(fields []field, k string) *field {
+	var fold *field
+	for i := range fields {
+		f := &fields[i]
+		if f.name == k {
+			return f
+		}
+		if fold == nil && strings.EqualFold(f.name, k) {
+			fold = f
+		}
+	}
+	return fold
+}
+
+// fieldByIndex returns the field f of the struct v
+// allocating any nil embedded pointers on the way.
+func (s *intoState) fieldByIndex(v reflect.Value, f *field) (reflect.Value, error) {
+	for _, i := range f.index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("Into: cannot set embedded pointer to unexported struct %s at %q", v.Type().Elem(), JoinKey("", s.path...))
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, nil
+}
+
+// intoQuoted stores the value encoded inside the string v
+// for fields with the string option.
+func (s *intoState) intoQuoted(v valueInterface, dst reflect.Value) error {
+	text, ok := v.(str)
+	if !ok {
+		if IsNull(v) {
+			return s.into(v, dst)
+		}
+		return s.typeError(v, dst.Type())
+	}
+	q, err := DecoderOptions{
+		DisallowTrailingData: true,
+		StrictNumbers:        true,
+		UseNumber:            true,
+	}.NewDecoder(strings.NewReader(string(text))).Decode()
+	if err != nil {
+		return s.typeError(v, dst.Type())
+	}
+	switch q.(type) {
+	case array, object:
+		return s.typeError(v, dst.Type())
+	}
+	return s.into(q.(valueInterface), dst)
+}
+
+// asValue returns the element e of an array or object as a value.
+func asValue(e any) valueInterface {
+	v, _ := e.(valueInterface)
+	return v
+}
+
+// plainValue returns the value v as the Go value json.Unmarshal
+// would store into an interface.
+func plainValue(v valueInterface) any {
+	switch v := materialize(v).(type) {
+	case boolean:
+		return bool(v)
+	case num:
+		return float64(v)
+	case decimal:
+		return json.Number(v)
+	case str:
+		return string(v)
+	case array:
+		res := make([]any, len(v))
+		for i, e := range v {
+			res[i] = plainValue(asValue(e))
+		}
+		return res
+	case object, *orderedObject:
+		res := make(map[string]any)
+		v.Each(func(k, e any) bool {
+			res[k.(string)] = plainValue(asValue(e))
+			return true
+		})
+		return res
+	default:
+		return nil
+	}
+}
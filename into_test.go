@@ -0,0 +1,265 @@
+package jsong
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type intoInner struct {
+	N int `json:"n"`
+}
+
+type intoEmbedded struct {
+	E string `json:"e"`
+}
+
+type intoStruct struct {
+	Bool    bool              `json:"bool"`
+	Int     int8              `json:"int"`
+	Uint    uint              `json:"uint"`
+	Float   float32           `json:"float"`
+	String  string            `json:"string,omitempty"`
+	Bytes   []byte            `json:"bytes"`
+	Slice   []intoInner       `json:"slice"`
+	Array   [2]int            `json:"array"`
+	Map     map[string]*int   `json:"map"`
+	IntMap  map[int]string    `json:"int_map"`
+	Ptr     *intoInner        `json:"ptr"`
+	Any     any               `json:"any"`
+	Number  json.Number       `json:"number"`
+	Skipped string            `json:"-"`
+	Default string            // Matched by field name.
+	Nested  map[string][]bool `json:"nested"`
+	private int
+
+	intoEmbedded `json:""`
+}
+
+func TestInto(t *testing.T) {
+	input := `{
+		"bool": true,
+		"int": -8,
+		"uint": 8,
+		"float": 1.5,
+		"string": "s",
+		"bytes": "b",
+		"slice": [{"n": 1}, {"N": 2}],
+		"array": [1, 2, 3],
+		"map": {"a": 1, "b": null},
+		"int_map": {"-1": "x"},
+		"ptr": {"n": 3},
+		"any": {"a": [1, "2", true, null]},
+		"number": 12345678901234567890,
+		"Skipped": "x",
+		"default": "d",
+		"nested": {"a": [true, false]},
+		"private": 1,
+		"e": "embedded",
+		"unknown": 1
+	}`
+	v, err := DecoderOptions{UseNumber: true}.NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("Decode(): got err = %v, want err = false", err)
+	}
+
+	got := intoStruct{Skipped: "keep", Map: map[string]*int{"c": new(int)}}
+	if err := Into(v, &got); err != nil {
+		t.Fatalf("Into(): got err = %v, want err = false", err)
+	}
+
+	one := 1
+	want := intoStruct{
+		Bool:         true,
+		Int:          -8,
+		Uint:         8,
+		Float:        1.5,
+		String:       "s",
+		Bytes:        []byte("b"),
+		Slice:        []intoInner{{1}, {2}},
+		Array:        [2]int{1, 2},
+		Map:          map[string]*int{"a": &one, "b": nil, "c": new(int)},
+		IntMap:       map[int]string{-1: "x"},
+		Ptr:          &intoInner{3},
+		Any:          map[string]any{"a": []any{json.Number("1"), "2", true, nil}},
+		Number:       "12345678901234567890",
+		Skipped:      "keep",
+		Default:      "d",
+		Nested:       map[string][]bool{"a": {true, false}},
+		intoEmbedded: intoEmbedded{"embedded"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(intoStruct{})); diff != "" {
+		t.Errorf("Into(): got diff:\n%s", diff)
+	}
+}
+
+type intoRoundTrip struct {
+	Bool   bool
+	Int    int8
+	Uint   uint32
+	Float  float64
+	Slice  []intoInner
+	Array  [2]string
+	Map    map[string]*intoInner
+	Ptr    *intoInner
+	Any    any
+	Nested map[string][]bool `json:"nested,omitempty"`
+}
+
+func TestIntoRoundTrip(t *testing.T) {
+	want := intoRoundTrip{
+		Bool:  true,
+		Int:   math.MinInt8,
+		Uint:  math.MaxUint32,
+		Float: 0.25,
+		Slice: []intoInner{{1}},
+		Array: [2]string{"a", "b"},
+		Map:   map[string]*intoInner{"a": {2}, "b": nil},
+		Ptr:   &intoInner{},
+		Any:   []any{1.5, "a"},
+	}
+
+	var got intoRoundTrip
+	if err := Into(ValueOf(want), &got); err != nil {
+		t.Fatalf("Into(): got err = %v, want err = false", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Into(): got diff:\n%s", diff)
+	}
+}
+
+func TestIntoNull(t *testing.T) {
+	n := 1
+	got := struct {
+		P *int
+		S []int
+		I int
+	}{&n, []int{1}, 1}
+
+	if err := Into(object{"P": null{}, "S": null{}, "I": null{}}, &got); err != nil {
+		t.Fatalf("Into(): got err = %v, want err = false", err)
+	}
+	if got.P != nil || got.S != nil || got.I != 1 {
+		t.Errorf("Into(): got %+v, want nil pointer and slice and unchanged int", got)
+	}
+}
+
+type intoErrorTestCase struct {
+	name     string
+	input    any
+	dst      any
+	wantErr  *IntoTypeError
+	wantType reflect.Type
+}
+
+func (tc intoErrorTestCase) runTest(t *testing.T) {
+	t.Helper()
+	err := Into(tc.input, tc.dst)
+	var gotErr *IntoTypeError
+	if !errors.As(err, &gotErr) {
+		t.Fatalf("Into(%q): got err = %v, want *IntoTypeError", tc.name, err)
+	}
+	if diff := cmp.Diff(tc.wantErr, gotErr, cmp.Comparer(func(a, b reflect.Type) bool { return a == b })); diff != "" {
+		t.Errorf("Into(%q): got diff:\n%s", tc.name, diff)
+	}
+}
+
+func TestIntoTypeError(t *testing.T) {
+	for _, tc := range []intoErrorTestCase{{
+		name:    "string into int",
+		input:   str("a"),
+		dst:     new(int),
+		wantErr: &IntoTypeError{Value: "string", Type: reflect.TypeOf(0)},
+	}, {
+		name:    "fraction into int",
+		input:   object{"a": array{num(1), num(1.5)}},
+		dst:     new(map[string][]int),
+		wantErr: &IntoTypeError{Value: "number 1.5", Type: reflect.TypeOf(0), Path: "a.1"},
+	}, {
+		name:    "overflow",
+		input:   object{"slice": array{object{"n": decimal("1e400")}}},
+		dst:     new(intoStruct),
+		wantErr: &IntoTypeError{Value: "number 1e400", Type: reflect.TypeOf(0), Path: "slice.0.n"},
+	}, {
+		name:    "int8 overflow",
+		input:   object{"int": num(128)},
+		dst:     new(intoStruct),
+		wantErr: &IntoTypeError{Value: "number 128", Type: reflect.TypeOf(int8(0)), Path: "int"},
+	}, {
+		name:    "negative into uint",
+		input:   num(-1),
+		dst:     new(uint),
+		wantErr: &IntoTypeError{Value: "number -1", Type: reflect.TypeOf(uint(0))},
+	}, {
+		name:    "object into slice",
+		input:   object{"slice": object{}},
+		dst:     new(intoStruct),
+		wantErr: &IntoTypeError{Value: "object", Type: reflect.TypeOf([]intoInner{}), Path: "slice"},
+	}, {
+		name:    "bad map key",
+		input:   object{"int_map": object{"x": str("")}},
+		dst:     new(intoStruct),
+		wantErr: &IntoTypeError{Value: "string", Type: reflect.TypeOf(0), Path: "int_map.x"},
+	}, {
+		name:    "non-empty interface",
+		input:   num(1),
+		dst:     new(error),
+		wantErr: &IntoTypeError{Value: "number 1", Type: reflect.TypeOf((*error)(nil)).Elem()},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
+
+func TestIntoNonPointer(t *testing.T) {
+	if err := Into(num(1), 1); err == nil {
+		t.Errorf("Into(): got err = nil, want err = true")
+	}
+	if err := Into(num(1), (*int)(nil)); err == nil {
+		t.Errorf("Into(): got err = nil, want err = true")
+	}
+}
+
+type intoUnmarshalers struct {
+	Time    time.Time
+	IP      net.IP
+	Big     *big.Int
+	Raw     json.RawMessage
+	TextMap map[netip.Addr]int
+}
+
+func TestIntoUnmarshalers(t *testing.T) {
+	want := intoUnmarshalers{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		IP:      net.IPv4(127, 0, 0, 1),
+		Big:     new(big.Int).Lsh(big.NewInt(1), 100),
+		Raw:     json.RawMessage(`{"a":[1,2]}`),
+		TextMap: map[netip.Addr]int{netip.MustParseAddr("::1"): 1},
+	}
+
+	var got intoUnmarshalers
+	if err := Into(ValueOptions{ExactNumbers: true}.ValueOf(&want), &got); err != nil {
+		t.Fatalf("Into(): got err = %v, want err = false", err)
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b *big.Int) bool { return a.Cmp(b) == 0 }), cmp.Comparer(func(a, b netip.Addr) bool { return a == b })); diff != "" {
+		t.Errorf("Into(): got diff:\n%s", diff)
+	}
+}
+
+func TestIntoUnmarshalerError(t *testing.T) {
+	var got struct{ Time time.Time }
+	err := Into(object{"Time": str("yesterday")}, &got)
+	if err == nil || !strings.Contains(err.Error(), `"Time"`) {
+		t.Errorf("Into(): got err = %v, want error at \"Time\"", err)
+	}
+}
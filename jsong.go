@@ -44,11 +44,44 @@ func Bool(v any) (bool, bool) {
 }
 
 func Float64(v any) (float64, bool) {
-	n, ok := v.(num)
+	switch n := v.(type) {
+	case num:
+		return float64(n), true
+	case decimal:
+		return n.Float64(), true
+	default:
+		return 0, false
+	}
+}
+
+// Int64 returns the number v as an int64.
+// It reports false if v is not a number or not an integer in range.
+func Int64(v any) (int64, bool) {
+	n, ok := v.(valueInterface)
+	if !ok {
+		return 0, false
+	}
+	return int64Value(n)
+}
+
+// Uint64 returns the number v as an uint64.
+// It reports false if v is not a number or not an integer in range.
+func Uint64(v any) (uint64, bool) {
+	n, ok := v.(valueInterface)
 	if !ok {
 		return 0, false
 	}
-	return float64(n), true
+	return uint64Value(n)
+}
+
+// Number returns the JSON text of the number v.
+// Exact numbers keep the text of their literal.
+func Number(v any) (string, bool) {
+	n, ok := v.(valueInterface)
+	if !ok {
+		return "", false
+	}
+	return numberText(n)
 }
 
 func String(v any) (string, bool) {
@@ -59,20 +92,33 @@ func String(v any) (string, bool) {
 	return string(s), true
 }
 
+// Array returns the elements of the array v.
+// Persistent arrays return a copy of their elements.
 func Array(v any) ([]any, bool) {
-	a, ok := v.(array)
-	if !ok {
+	switch a := v.(type) {
+	case array:
+		return ([]any)(a), true
+	case *persistentArray:
+		return ([]any)(materialize(a).(array)), true
+	default:
 		return nil, false
 	}
-	return ([]any)(a), true
 }
 
+// Object returns the entries of the object v.
+// The map of an ordered object must not be modified.
+// Persistent objects return a copy of their entries.
 func Object(v any) (map[string]any, bool) {
-	m, ok := v.(object)
-	if !ok {
+	switch m := v.(type) {
+	case object:
+		return (map[string]any)(m), true
+	case *orderedObject:
+		return (map[string]any)(m.m), true
+	case *persistentObject:
+		return (map[string]any)(materialize(m).(object)), true
+	default:
 		return nil, false
 	}
-	return (map[string]any)(m), true
 }
 
 type null struct{}
@@ -110,8 +156,9 @@ func (a array) At(i int) valueInterface {
 }
 
 func (a array) Get(k any) (valueInterface, bool) {
-	if i, ok := k.(int64); ok && i < int64(len(a)) {
-		return a[i].(valueInterface), true
+	if i, ok := k.(int64); ok && i >= 0 && i < int64(len(a)) {
+		v, ok := a[i].(valueInterface) // Deleted elements are nil.
+		return v, ok
 	}
 	return nil, false
 }
@@ -125,7 +172,7 @@ func (a array) Put(k any, v valueInterface) {
 }
 
 func (a array) Delete(k any) {
-	if i, ok := k.(int64); ok && i < int64(len(a)) {
+	if i, ok := k.(int64); ok && i >= 0 && i < int64(len(a)) {
 		a[i] = nil
 	}
 }
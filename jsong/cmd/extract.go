@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -14,6 +14,9 @@ var extractFlags struct {
 	Input  string
 	Format string
 	Path   string
+	Indent string
+	Sort   bool
+	NDJSON bool
 }
 
 var extractCmd = &cobra.Command{
@@ -25,24 +28,43 @@ var extractCmd = &cobra.Command{
 			return fmt.Errorf("failed to read from file: %v", err)
 		}
 		defer f.Close()
-		var v any
 		switch strings.ToLower(extractFlags.Format) {
 		case "", "json":
-			v, err = jsong.NewDecoder(f).Decode()
-			if err != nil {
-				return fmt.Errorf("failed to decode file: %v", err)
-			}
 		default:
 			return fmt.Errorf("unexpected format: %q", extractFlags.Format)
 		}
 
-		res := jsong.Extract(v, extractFlags.Path)
-		data, err := json.Marshal(res)
-		if err != nil {
-			return fmt.Errorf("failed to marshal output: %v", err)
+		// Pass numbers and key order through unchanged.
+		dec := jsong.DecoderOptions{
+			UseNumber:      true,
+			OrderedObjects: true,
+		}.NewDecoder(f)
+		enc := jsong.EncoderOptions{
+			Indent:   extractFlags.Indent,
+			SortKeys: extractFlags.Sort,
+		}.NewEncoder(os.Stdout)
+		paths := []string{extractFlags.Path}
+		if strings.HasPrefix(extractFlags.Path, "/") {
+			// JSON Pointer tokens may be keys or indices
+			// so decode the whole value.
+			paths = []string{"**"}
+		}
+		for {
+			// Only decode the data needed for the path.
+			v, err := dec.DecodePaths(paths...)
+			if err == io.EOF && extractFlags.NDJSON {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to decode file: %v", err)
+			}
+			if err := enc.Encode(jsong.Extract(v, extractFlags.Path)); err != nil {
+				return fmt.Errorf("failed to encode output: %v", err)
+			}
+			if !extractFlags.NDJSON {
+				break
+			}
 		}
-
-		fmt.Println(string(data))
 		return nil
 	},
 }
@@ -51,6 +73,9 @@ func init() {
 	fs := extractCmd.Flags()
 	fs.StringVarP(&extractFlags.Input, "input", "i", "", "Input file name")
 	fs.StringVarP(&extractFlags.Format, "format", "f", "json", "Input file format")
-	fs.StringVarP(&extractFlags.Path, "path", "p", "", "Path to extract")
+	fs.StringVarP(&extractFlags.Path, "path", "p", "", "Path or JSON Pointer to extract")
+	fs.StringVar(&extractFlags.Indent, "indent", "", "Indent string for output")
+	fs.BoolVar(&extractFlags.Sort, "sortkeys", false, "Sort output object keys")
+	fs.BoolVar(&extractFlags.NDJSON, "ndjson", false, "Extract from each value in a newline delimited JSON input")
 	extractCmd.MarkFlagRequired("input")
 }
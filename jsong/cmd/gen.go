@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wenooij/jsong/internal/gen"
+)
+
+var genFlags struct {
+	Types  string
+	Output string
+}
+
+var genCmd = &cobra.Command{
+	Use:   "gen [dir]",
+	Short: "Generate JSONGValue methods for struct types",
+	Long: `Generate JSONGValue methods converting struct types to jsong values without reflection.
+
+The methods are written to a file in the package directory (default ".") and
+give the same values as jsong.ValueOf. Use it with go generate:
+
+	//go:generate jsong gen --type Event,User`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		types := strings.Split(genFlags.Types, ",")
+		output := genFlags.Output
+		if output == "" {
+			output = strings.ToLower(types[0]) + "_jsong.go"
+		}
+		output = filepath.Join(dir, output)
+		src, err := gen.Generate(dir, output, types...)
+		if err != nil {
+			return fmt.Errorf("failed to generate: %v", err)
+		}
+		if err := os.WriteFile(output, src, 0o644); err != nil {
+			return fmt.Errorf("failed to write output: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	fs := genCmd.Flags()
+	fs.StringVarP(&genFlags.Types, "type", "t", "", "Comma-separated list of struct type names")
+	fs.StringVarP(&genFlags.Output, "output", "o", "", "Output file name in the package directory (default <type>_jsong.go)")
+	genCmd.MarkFlagRequired("type")
+}
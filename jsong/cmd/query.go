@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wenooij/jsong"
+)
+
+var queryFlags struct {
+	Input  string
+	Format string
+	Indent string
+	Sort   bool
+	Paths  bool
+	NDJSON bool
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query <jsonpath>",
+	Short: "Print JSON values selected by a JSONPath query",
+	Long: `Print JSON values selected by a JSONPath (RFC 9535) query.
+
+Each selected value is printed in document order like
+	jsong query -i store.json '$.store.book[?@.price < 10].title'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jp, err := jsong.CompileJSONPath(args[0])
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(queryFlags.Input)
+		if err != nil {
+			return fmt.Errorf("failed to read from file: %v", err)
+		}
+		defer f.Close()
+		switch strings.ToLower(queryFlags.Format) {
+		case "", "json":
+		default:
+			return fmt.Errorf("unexpected format: %q", queryFlags.Format)
+		}
+
+		// Pass numbers and key order through unchanged.
+		dec := jsong.DecoderOptions{
+			UseNumber:      true,
+			OrderedObjects: true,
+		}.NewDecoder(f)
+		enc := jsong.EncoderOptions{
+			Indent:   queryFlags.Indent,
+			SortKeys: queryFlags.Sort,
+		}.NewEncoder(os.Stdout)
+		for {
+			v, err := dec.Decode()
+			if err == io.EOF && queryFlags.NDJSON {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to decode file: %v", err)
+			}
+			for _, n := range jp.Query(v) {
+				out := n.Value
+				if queryFlags.Paths {
+					b := jsong.ValueOptions{OrderedObjects: true}.NewBuilder()
+					out, err = b.Obj().
+						Set("path", n.Path.JSONPath()).
+						Set("value", n.Value).
+						End().Build()
+					if err != nil {
+						return fmt.Errorf("failed to build output: %v", err)
+					}
+				}
+				if err := enc.Encode(out); err != nil {
+					return fmt.Errorf("failed to encode output: %v", err)
+				}
+			}
+			if !queryFlags.NDJSON {
+				break
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	fs := queryCmd.Flags()
+	fs.StringVarP(&queryFlags.Input, "input", "i", "", "Input file name")
+	fs.StringVarP(&queryFlags.Format, "format", "f", "json", "Input file format")
+	fs.StringVar(&queryFlags.Indent, "indent", "", "Indent string for output")
+	fs.BoolVar(&queryFlags.Sort, "sortkeys", false, "Sort output object keys")
+	fs.BoolVar(&queryFlags.Paths, "paths", false, "Print each value as an object with its normalized path")
+	fs.BoolVar(&queryFlags.NDJSON, "ndjson", false, "Query each value in a newline delimited JSON input")
+	queryCmd.MarkFlagRequired("input")
+}
@@ -59,6 +59,8 @@ func init() {
 	fs.StringVar(&rootFlags.MemProfile, "memprofile", "", "Mem profile")
 	rootCmd.AddCommand(
 		extractCmd,
+		genCmd,
+		queryCmd,
 	)
 }
 
@@ -0,0 +1,677 @@
+package jsong
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONPath is a compiled JSONPath query (RFC 9535) like
+// "$.items[?@.price < 10].name" or "$..id".
+//
+// Queries support name, wildcard, index, slice and filter selectors,
+// child and descendant segments and the function extensions length,
+// count, match, search and value.
+type JSONPath struct {
+	query string
+	q     *jsonPathQuery
+}
+
+// JSONPathNode is a value selected by a JSONPath query.
+type JSONPathNode struct {
+	Path  Path // Location of the value. See Path.JSONPath.
+	Value any
+}
+
+// CompileJSONPath parses the JSONPath query
+// and returns an error if it is not valid.
+func CompileJSONPath(query string) (*JSONPath, error) {
+	p := &jsonPathParser{s: query}
+	q, err := p.parseRootQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPath{query: query, q: q}, nil
+}
+
+// String returns the query of the JSONPath.
+func (jp *JSONPath) String() string { return jp.query }
+
+// Query returns the nodes selected by the query in the value v.
+//
+// Object members are visited in insertion order for ordered objects
+// and sorted key order otherwise. Go values are converted with ValueOf.
+// Values which ValueOf cannot convert select nothing.
+func (jp *JSONPath) Query(v any) []JSONPathNode {
+	var res []JSONPathNode
+	rv := valueOf(v)
+	if rv == nil {
+		return nil
+	}
+	root := jsonPathValue(rv)
+	jp.q.eval(root, root, func(n *jsonPathNode) {
+		res = append(res, JSONPathNode{Path: n.path(), Value: n.v})
+	})
+	return res
+}
+
+// Values returns the values selected by the query in the value v.
+func (jp *JSONPath) Values(v any) []any {
+	var res []any
+	rv := valueOf(v)
+	if rv == nil {
+		return nil
+	}
+	root := jsonPathValue(rv)
+	jp.q.eval(root, root, func(n *jsonPathNode) { res = append(res, n.v) })
+	return res
+}
+
+// JSONPath returns p as a normalized JSONPath like "$['a'][0]".
+// Negative indices and slices are written as index and slice
+// selectors which are not normalized.
+//
+// Keys should be either string, int64 or Slice
+// or else JSONPath panics.
+func (p Path) JSONPath() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, k := range p {
+		switch k := k.(type) {
+		case int64:
+			sb.WriteByte('[')
+			sb.WriteString(strconv.FormatInt(k, 10))
+			sb.WriteByte(']')
+		case Slice:
+			sb.WriteByte('[')
+			sb.WriteString(k.String())
+			sb.WriteByte(']')
+		case string:
+			sb.WriteString("['")
+			writeNormalizedName(&sb, k)
+			sb.WriteString("']")
+		default:
+			panic(fmt.Errorf("Path.JSONPath: unexpected type in key at %T", k))
+		}
+	}
+	return sb.String()
+}
+
+// writeNormalizedName writes the name k escaped
+// for a normalized path.
+func writeNormalizedName(sb *strings.Builder, k string) {
+	for i := 0; i < len(k); i++ {
+		switch c := k[i]; c {
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\'', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			if c < 0x20 {
+				sb.WriteString(`\u00`)
+				sb.WriteByte(hex[c>>4])
+				sb.WriteByte(hex[c&0xf])
+				continue
+			}
+			sb.WriteByte(c)
+		}
+	}
+}
+
+// jsonPathNode is a node selected by a query.
+// The node links to its parent for its path.
+type jsonPathNode struct {
+	v      valueInterface
+	parent *jsonPathNode
+	key    any
+}
+
+func (n *jsonPathNode) child(k any, v valueInterface) *jsonPathNode {
+	return &jsonPathNode{v: v, parent: n, key: k}
+}
+
+func (n *jsonPathNode) path() Path {
+	depth := 0
+	for m := n; m.parent != nil; m = m.parent {
+		depth++
+	}
+	if depth == 0 {
+		return nil
+	}
+	p := make(Path, depth)
+	for m := n; m.parent != nil; m = m.parent {
+		depth--
+		p[depth] = m.key
+	}
+	return p
+}
+
+// jsonPathValue returns v or null if v is nil.
+func jsonPathValue(v any) valueInterface {
+	if v, ok := v.(valueInterface); ok {
+		return v
+	}
+	return null{}
+}
+
+// jsonPathGet returns the element k of v
+// with nil elements as null.
+func jsonPathGet(v valueInterface, k any) (valueInterface, bool) {
+	var e any
+	var ok bool
+	switch v := v.(type) {
+	case array:
+		i, isIndex := k.(int64)
+		if ok = isIndex && i >= 0 && i < int64(len(v)); ok {
+			e = v[i]
+		}
+	case object:
+		if s, isKey := k.(string); isKey {
+			e, ok = v[s]
+		}
+	case *orderedObject:
+		if s, isKey := k.(string); isKey {
+			e, ok = v.m[s]
+		}
+	default:
+		e, ok = v.Get(k)
+	}
+	if !ok {
+		return nil, false
+	}
+	return jsonPathValue(e), true
+}
+
+// eachChild calls fn for the elements of an array
+// or members of an object in order.
+func eachChild(n *jsonPathNode, fn func(*jsonPathNode)) {
+	switch KindOf(n.v) {
+	case KindArray:
+		n.v.Each(func(k, e any) bool {
+			fn(n.child(k, jsonPathValue(e)))
+			return true
+		})
+	case KindObject:
+		type member struct {
+			k string
+			v any
+		}
+		var ms []member
+		n.v.Each(func(k, e any) bool {
+			ms = append(ms, member{k.(string), e})
+			return true
+		})
+		if _, ok := n.v.(*orderedObject); !ok {
+			slices.SortFunc(ms, func(a, b member) int { return strings.Compare(a.k, b.k) })
+		}
+		for _, m := range ms {
+			fn(n.child(m.k, jsonPathValue(m.v)))
+		}
+	}
+}
+
+// jsonPathQuery is a query from the root or,
+// in filters, relative to the current node.
+type jsonPathQuery struct {
+	relative bool
+	segments []jsonPathSegment
+}
+
+type jsonPathSegment struct {
+	descendant bool
+	selectors  []jsonPathSelector
+}
+
+type jsonPathSelector interface {
+	// selectFrom calls emit for the nodes selected from n.
+	// The root is the value of $ in filters.
+	selectFrom(root valueInterface, n *jsonPathNode, emit func(*jsonPathNode))
+}
+
+// eval calls emit for the nodes selected by q.
+func (q *jsonPathQuery) eval(root, cur valueInterface, emit func(*jsonPathNode)) {
+	start := root
+	if q.relative {
+		start = cur
+	}
+	q.evalFrom(root, &jsonPathNode{v: start}, 0, emit)
+}
+
+func (q *jsonPathQuery) evalFrom(root valueInterface, n *jsonPathNode, i int, emit func(*jsonPathNode)) {
+	if i == len(q.segments) {
+		emit(n)
+		return
+	}
+	next := func(m *jsonPathNode) { q.evalFrom(root, m, i+1, emit) }
+	if seg := q.segments[i]; seg.descendant {
+		seg.descend(root, n, next)
+	} else {
+		for _, s := range seg.selectors {
+			s.selectFrom(root, n, next)
+		}
+	}
+}
+
+// descend applies the selectors to n and its descendants in order.
+func (seg jsonPathSegment) descend(root valueInterface, n *jsonPathNode, emit func(*jsonPathNode)) {
+	for _, s := range seg.selectors {
+		s.selectFrom(root, n, emit)
+	}
+	eachChild(n, func(c *jsonPathNode) { seg.descend(root, c, emit) })
+}
+
+// singular reports whether q selects at most one node.
+func (q *jsonPathQuery) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case jsonPathName, jsonPathIndex:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// nodes returns the values of the nodes selected by q.
+func (q *jsonPathQuery) nodes(root, cur valueInterface) []valueInterface {
+	var res []valueInterface
+	q.eval(root, cur, func(n *jsonPathNode) { res = append(res, n.v) })
+	return res
+}
+
+type jsonPathName string
+
+func (s jsonPathName) selectFrom(_ valueInterface, n *jsonPathNode, emit func(*jsonPathNode)) {
+	if KindOf(n.v) != KindObject {
+		return
+	}
+	if e, ok := jsonPathGet(n.v, string(s)); ok {
+		emit(n.child(string(s), e))
+	}
+}
+
+type jsonPathWildcard struct{}
+
+func (jsonPathWildcard) selectFrom(_ valueInterface, n *jsonPathNode, emit func(*jsonPathNode)) {
+	eachChild(n, emit)
+}
+
+type jsonPathIndex int64
+
+func (s jsonPathIndex) selectFrom(_ valueInterface, n *jsonPathNode, emit func(*jsonPathNode)) {
+	if KindOf(n.v) != KindArray {
+		return
+	}
+	i := int64(s)
+	if i < 0 {
+		i += int64(View(n.v).Len())
+	}
+	if e, ok := jsonPathGet(n.v, i); ok {
+		emit(n.child(i, e))
+	}
+}
+
+type jsonPathSlice struct {
+	start, end       int64
+	hasStart, hasEnd bool
+	step             int64
+}
+
+func (s jsonPathSlice) selectFrom(_ valueInterface, n *jsonPathNode, emit func(*jsonPathNode)) {
+	if KindOf(n.v) != KindArray || s.step == 0 {
+		return
+	}
+	length := int64(View(n.v).Len())
+	normalize := func(i int64) int64 {
+		if i < 0 {
+			return length + i
+		}
+		return i
+	}
+	clamp := func(i, lo, hi int64) int64 { return min(max(i, lo), hi) }
+	start, end := int64(0), length
+	if s.step < 0 {
+		start, end = length-1, -length-1
+	}
+	if s.hasStart {
+		start = s.start
+	}
+	if s.hasEnd {
+		end = s.end
+	}
+	start, end = normalize(start), normalize(end)
+	if s.step > 0 {
+		lower, upper := clamp(start, 0, length), clamp(end, 0, length)
+		for i := lower; i < upper; i += s.step {
+			e, _ := jsonPathGet(n.v, i)
+			emit(n.child(i, e))
+		}
+		return
+	}
+	upper, lower := clamp(start, -1, length-1), clamp(end, -1, length-1)
+	for i := upper; lower < i; i += s.step {
+		e, _ := jsonPathGet(n.v, i)
+		emit(n.child(i, e))
+	}
+}
+
+type jsonPathFilter struct {
+	expr jsonPathLogical
+}
+
+func (s jsonPathFilter) selectFrom(root valueInterface, n *jsonPathNode, emit func(*jsonPathNode)) {
+	eachChild(n, func(c *jsonPathNode) {
+		if s.expr.test(root, c.v) {
+			emit(c)
+		}
+	})
+}
+
+// jsonPathLogical is a filter expression of logical type.
+type jsonPathLogical interface {
+	test(root, cur valueInterface) bool
+}
+
+// jsonPathValueExpr is a filter expression of value type.
+// It reports false when the value is Nothing.
+type jsonPathValueExpr interface {
+	value(root, cur valueInterface) (valueInterface, bool)
+}
+
+type jsonPathOr []jsonPathLogical
+
+func (x jsonPathOr) test(root, cur valueInterface) bool {
+	for _, y := range x {
+		if y.test(root, cur) {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonPathAnd []jsonPathLogical
+
+func (x jsonPathAnd) test(root, cur valueInterface) bool {
+	for _, y := range x {
+		if !y.test(root, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+type jsonPathNot struct {
+	x jsonPathLogical
+}
+
+func (x jsonPathNot) test(root, cur valueInterface) bool { return !x.x.test(root, cur) }
+
+// jsonPathExists tests whether a query selects any node.
+type jsonPathExists struct {
+	q *jsonPathQuery
+}
+
+func (x jsonPathExists) test(root, cur valueInterface) bool {
+	found := false
+	x.q.eval(root, cur, func(*jsonPathNode) { found = true })
+	return found
+}
+
+type jsonPathComparison struct {
+	op   string
+	a, b jsonPathValueExpr
+}
+
+func (x *jsonPathComparison) test(root, cur valueInterface) bool {
+	a, aok := x.a.value(root, cur)
+	b, bok := x.b.value(root, cur)
+	switch x.op {
+	case "==":
+		return jsonPathEqual(a, aok, b, bok)
+	case "!=":
+		return !jsonPathEqual(a, aok, b, bok)
+	case "<":
+		return jsonPathLess(a, aok, b, bok)
+	case "<=":
+		return jsonPathLess(a, aok, b, bok) || jsonPathEqual(a, aok, b, bok)
+	case ">":
+		return jsonPathLess(b, bok, a, aok)
+	default: // ">="
+		return jsonPathLess(b, bok, a, aok) || jsonPathEqual(a, aok, b, bok)
+	}
+}
+
+func jsonPathEqual(a valueInterface, aok bool, b valueInterface, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok // Nothing only equals Nothing.
+	}
+	return KindOf(a) == KindOf(b) && compare(a, b) == 0
+}
+
+// jsonPathLess reports whether a < b for numbers and strings.
+func jsonPathLess(a valueInterface, aok bool, b valueInterface, bok bool) bool {
+	if !aok || !bok || KindOf(a) != KindOf(b) {
+		return false
+	}
+	switch KindOf(a) {
+	case KindNumber, KindString:
+		return compare(a, b) < 0
+	default:
+		return false
+	}
+}
+
+type jsonPathLiteral struct {
+	v valueInterface
+}
+
+func (x jsonPathLiteral) value(_, _ valueInterface) (valueInterface, bool) { return x.v, true }
+
+// jsonPathSingular is the value of a singular query.
+type jsonPathSingular struct {
+	q *jsonPathQuery
+}
+
+func (x jsonPathSingular) value(root, cur valueInterface) (res valueInterface, ok bool) {
+	x.q.eval(root, cur, func(n *jsonPathNode) { res, ok = n.v, true })
+	return res, ok
+}
+
+// jsonPathType is the type of a function parameter or result.
+type jsonPathType uint8
+
+const (
+	jsonPathValueType jsonPathType = iota
+	jsonPathLogicalType
+	jsonPathNodesType
+)
+
+// jsonPathArg is a function argument or result.
+type jsonPathArg struct {
+	v       valueInterface // Value or nil for Nothing.
+	logical bool
+	nodes   []valueInterface
+
+	// The compiled regular expression of a literal pattern v
+	// of match or search if compiled is set.
+	re       *regexp.Regexp
+	compiled bool
+}
+
+type jsonPathFunction struct {
+	params []jsonPathType
+	result jsonPathType
+	call   func(args []jsonPathArg) jsonPathArg
+}
+
+var jsonPathFunctions = map[string]*jsonPathFunction{
+	"length": {
+		params: []jsonPathType{jsonPathValueType},
+		result: jsonPathValueType,
+		call: func(args []jsonPathArg) jsonPathArg {
+			switch v := args[0].v.(type) {
+			case str:
+				return jsonPathArg{v: num(utf8.RuneCountInString(string(v)))}
+			case nil:
+				return jsonPathArg{}
+			}
+			if kind := KindOf(args[0].v); kind == KindArray || kind == KindObject {
+				return jsonPathArg{v: num(View(args[0].v).Len())}
+			}
+			return jsonPathArg{}
+		},
+	},
+	"count": {
+		params: []jsonPathType{jsonPathNodesType},
+		result: jsonPathValueType,
+		call: func(args []jsonPathArg) jsonPathArg {
+			return jsonPathArg{v: num(len(args[0].nodes))}
+		},
+	},
+	"match": {
+		params: []jsonPathType{jsonPathValueType, jsonPathValueType},
+		result: jsonPathLogicalType,
+		call: func(args []jsonPathArg) jsonPathArg {
+			return jsonPathArg{logical: jsonPathRegexpMatch(args[0].v, args[1], true)}
+		},
+	},
+	"search": {
+		params: []jsonPathType{jsonPathValueType, jsonPathValueType},
+		result: jsonPathLogicalType,
+		call: func(args []jsonPathArg) jsonPathArg {
+			return jsonPathArg{logical: jsonPathRegexpMatch(args[0].v, args[1], false)}
+		},
+	},
+	"value": {
+		params: []jsonPathType{jsonPathNodesType},
+		result: jsonPathValueType,
+		call: func(args []jsonPathArg) jsonPathArg {
+			if len(args[0].nodes) != 1 {
+				return jsonPathArg{}
+			}
+			return jsonPathArg{v: args[0].nodes[0]}
+		},
+	},
+}
+
+// jsonPathCall is a function expression. Its arguments are
+// jsonPathValueExpr, jsonPathLogical or *jsonPathQuery values
+// for the value, logical and nodes parameters.
+type jsonPathCall struct {
+	name string
+	fn   *jsonPathFunction
+	args []any
+}
+
+func (x *jsonPathCall) call(root, cur valueInterface) jsonPathArg {
+	args := make([]jsonPathArg, len(x.args))
+	for i, arg := range x.args {
+		switch arg := arg.(type) {
+		case *jsonPathQuery:
+			args[i].nodes = arg.nodes(root, cur)
+		case jsonPathPattern:
+			args[i] = jsonPathArg{v: arg.v, re: arg.re, compiled: true}
+		case jsonPathValueExpr:
+			args[i].v, _ = arg.value(root, cur)
+		case jsonPathLogical:
+			args[i].logical = arg.test(root, cur)
+		}
+	}
+	return x.fn.call(args)
+}
+
+func (x *jsonPathCall) value(root, cur valueInterface) (valueInterface, bool) {
+	res := x.call(root, cur)
+	return res.v, res.v != nil
+}
+
+func (x *jsonPathCall) test(root, cur valueInterface) bool {
+	res := x.call(root, cur)
+	if x.fn.result == jsonPathNodesType {
+		return len(res.nodes) > 0
+	}
+	return res.logical
+}
+
+// jsonPathPattern is a string literal pattern of match or search
+// with its regular expression compiled with the query.
+type jsonPathPattern struct {
+	jsonPathLiteral
+	re *regexp.Regexp // Nil if the pattern is invalid.
+}
+
+// jsonPathRegexpMatch reports whether the string s matches the
+// I-Regexp (RFC 9485) pattern, entirely if full is set.
+//
+// Patterns from the queried value are compiled on each call
+// rather than cached since they may be unbounded.
+func jsonPathRegexpMatch(s valueInterface, pattern jsonPathArg, full bool) bool {
+	ss, ok := s.(str)
+	if !ok {
+		return false
+	}
+	r := pattern.re
+	if !pattern.compiled {
+		re, ok := pattern.v.(str)
+		if !ok {
+			return false
+		}
+		r = compileIRegexp(string(re), full)
+	}
+	return r != nil && r.MatchString(string(ss))
+}
+
+// compileIRegexp compiles the I-Regexp re, which must match
+// entirely if full is set. It returns nil if re is invalid.
+func compileIRegexp(re string, full bool) *regexp.Regexp {
+	expr := "(?:" + iregexp(re) + ")"
+	if full {
+		expr = "^" + expr + "$"
+	}
+	r, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// iregexp returns the I-Regexp re as a Go regular expression
+// where the dot doesn't match line breaks.
+func iregexp(re string) string {
+	var sb strings.Builder
+	inClass := false
+	for i := 0; i < len(re); i++ {
+		switch c := re[i]; {
+		case c == '\\' && i+1 < len(re):
+			sb.WriteByte(c)
+			i++
+			sb.WriteByte(re[i])
+		case c == '[':
+			inClass = true
+			sb.WriteByte(c)
+		case c == ']':
+			inClass = false
+			sb.WriteByte(c)
+		case c == '.' && !inClass:
+			sb.WriteString(`[^\n\r]`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,650 @@
+package jsong
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxJSONPathInt is the largest integer allowed in index
+// and slice selectors, the I-JSON limit 2^53-1.
+const maxJSONPathInt = 1<<53 - 1
+
+// jsonPathParser is a recursive descent parser
+// for the JSONPath grammar of RFC 9535.
+type jsonPathParser struct {
+	s   string
+	pos int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("CompileJSONPath: %s at offset %d in %q", fmt.Sprintf(format, args...), p.pos, p.s)
+}
+
+// peek returns the next byte or 0 at the end.
+func (p *jsonPathParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+// consume advances past tok if it is next.
+func (p *jsonPathParser) consume(tok string) bool {
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *jsonPathParser) skipBlank() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isNameFirst(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r == '_' || r >= 0x80
+}
+
+func (p *jsonPathParser) parseRootQuery() (*jsonPathQuery, error) {
+	if !p.consume("$") {
+		return nil, p.errorf("query must begin with $")
+	}
+	q := &jsonPathQuery{}
+	if err := p.parseSegments(q); err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return q, nil
+}
+
+// parseSegments parses the segments following $ or @.
+func (p *jsonPathParser) parseSegments(q *jsonPathQuery) error {
+	for {
+		start := p.pos
+		p.skipBlank()
+		var seg jsonPathSegment
+		var err error
+		switch {
+		case p.consume(".."):
+			seg.descendant = true
+			switch p.peek() {
+			case '[':
+				seg.selectors, err = p.parseBracket()
+			case '*':
+				p.pos++
+				seg.selectors = []jsonPathSelector{jsonPathWildcard{}}
+			default:
+				seg.selectors, err = p.parseMemberName()
+			}
+		case p.consume("."):
+			if p.consume("*") {
+				seg.selectors = []jsonPathSelector{jsonPathWildcard{}}
+			} else {
+				seg.selectors, err = p.parseMemberName()
+			}
+		case p.peek() == '[':
+			seg.selectors, err = p.parseBracket()
+		default:
+			p.pos = start
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		q.segments = append(q.segments, seg)
+	}
+}
+
+// parseMemberName parses the name of a shorthand like ".a".
+func (p *jsonPathParser) parseMemberName() ([]jsonPathSelector, error) {
+	start := p.pos
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		if r == utf8.RuneError && size == 1 {
+			return nil, p.errorf("invalid UTF-8")
+		}
+		if !isNameFirst(r) && (p.pos == start || !isDigit(byte(r))) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected member name")
+	}
+	return []jsonPathSelector{jsonPathName(p.s[start:p.pos])}, nil
+}
+
+// parseBracket parses the selectors of a bracketed segment like "[0, 'a']".
+func (p *jsonPathParser) parseBracket() ([]jsonPathSelector, error) {
+	p.pos++ // '['
+	var sels []jsonPathSelector
+	for {
+		p.skipBlank()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipBlank()
+		if p.consume("]") {
+			return sels, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *jsonPathParser) parseSelector() (jsonPathSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathName(s), nil
+	case c == '*':
+		p.pos++
+		return jsonPathWildcard{}, nil
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		expr, err := p.logical(x)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathFilter{expr}, nil
+	}
+	return p.parseIndexOrSlice()
+}
+
+func (p *jsonPathParser) parseIndexOrSlice() (jsonPathSelector, error) {
+	var s jsonPathSlice
+	var err error
+	if c := p.peek(); c == '-' || isDigit(c) {
+		if s.start, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		s.hasStart = true
+	}
+	start := p.pos
+	p.skipBlank()
+	if !p.consume(":") {
+		p.pos = start
+		if !s.hasStart {
+			return nil, p.errorf("expected selector")
+		}
+		return jsonPathIndex(s.start), nil
+	}
+	p.skipBlank()
+	if c := p.peek(); c == '-' || isDigit(c) {
+		if s.end, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		s.hasEnd = true
+	}
+	s.step = 1
+	start = p.pos
+	p.skipBlank()
+	if !p.consume(":") {
+		p.pos = start
+		return s, nil
+	}
+	p.skipBlank()
+	if c := p.peek(); c == '-' || isDigit(c) {
+		if s.step, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseInt parses an integer without leading zeros
+// in the range of maxJSONPathInt.
+func (p *jsonPathParser) parseInt() (int64, error) {
+	start := p.pos
+	p.consume("-")
+	switch c := p.peek(); {
+	case c == '0':
+		p.pos++
+		if p.pos-start == 2 {
+			return 0, p.errorf("invalid integer -0")
+		}
+		if isDigit(p.peek()) {
+			return 0, p.errorf("invalid integer with leading zero")
+		}
+	case isDigit(c):
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	default:
+		return 0, p.errorf("expected integer")
+	}
+	i, err := strconv.ParseInt(p.s[start:p.pos], 10, 64)
+	if err != nil || i < -maxJSONPathInt || i > maxJSONPathInt {
+		return 0, p.errorf("integer %s out of range", p.s[start:p.pos])
+	}
+	return i, nil
+}
+
+// parseString parses a single or double quoted string literal.
+func (p *jsonPathParser) parseString() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos == len(p.s) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.s[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c < 0x20:
+			return "", p.errorf("invalid control character in string")
+		case c == '\\':
+			if err := p.parseEscape(&sb, quote); err != nil {
+				return "", err
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return "", p.errorf("invalid UTF-8")
+			}
+			sb.WriteString(p.s[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+}
+
+func (p *jsonPathParser) parseEscape(sb *strings.Builder, quote byte) error {
+	p.pos++ // '\\'
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case '/', '\\', quote:
+		sb.WriteByte(c)
+	case 'u':
+		r, err := p.parseHex4()
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(r) {
+			if r >= 0xdc00 || !p.consume(`\u`) {
+				return p.errorf("invalid surrogate")
+			}
+			r2, err := p.parseHex4()
+			if err != nil {
+				return err
+			}
+			if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+				return p.errorf("invalid surrogate")
+			}
+		}
+		sb.WriteRune(r)
+	default:
+		p.pos--
+		return p.errorf("invalid escape")
+	}
+	return nil
+}
+
+func (p *jsonPathParser) parseHex4() (rune, error) {
+	if p.pos+4 > len(p.s) {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	r, err := strconv.ParseUint(p.s[p.pos:p.pos+4], 16, 16)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.pos += 4
+	return rune(r), nil
+}
+
+// Filter expressions are parsed into one of
+// *jsonPathQuery, jsonPathLiteral, *jsonPathCall or jsonPathLogical
+// and then converted to the type expected by their context.
+
+func (p *jsonPathParser) parseOr() (any, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	var terms jsonPathOr
+	for {
+		start := p.pos
+		p.skipBlank()
+		if !p.consume("||") {
+			p.pos = start
+			break
+		}
+		p.skipBlank()
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if terms, err = p.appendLogical(terms, x, y); err != nil {
+			return nil, err
+		}
+	}
+	if terms == nil {
+		return x, nil
+	}
+	return terms, nil
+}
+
+func (p *jsonPathParser) parseAnd() (any, error) {
+	x, err := p.parseBasic()
+	if err != nil {
+		return nil, err
+	}
+	var terms jsonPathAnd
+	for {
+		start := p.pos
+		p.skipBlank()
+		if !p.consume("&&") {
+			p.pos = start
+			break
+		}
+		p.skipBlank()
+		y, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		if terms, err = p.appendLogical(terms, x, y); err != nil {
+			return nil, err
+		}
+	}
+	if terms == nil {
+		return x, nil
+	}
+	return terms, nil
+}
+
+// appendLogical appends y to terms as a logical expression
+// converting the first term x when terms is empty.
+func (p *jsonPathParser) appendLogical(terms []jsonPathLogical, x, y any) ([]jsonPathLogical, error) {
+	if terms == nil {
+		l, err := p.logical(x)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, l)
+	}
+	l, err := p.logical(y)
+	if err != nil {
+		return nil, err
+	}
+	return append(terms, l), nil
+}
+
+func (p *jsonPathParser) parseBasic() (any, error) {
+	switch p.peek() {
+	case '!':
+		p.pos++
+		p.skipBlank()
+		var x any
+		var err error
+		if p.peek() == '(' {
+			x, err = p.parseParen()
+		} else {
+			x, err = p.parsePrimary()
+		}
+		if err != nil {
+			return nil, err
+		}
+		l, err := p.logical(x)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathNot{l}, nil
+	case '(':
+		return p.parseParen()
+	}
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	start := p.pos
+	p.skipBlank()
+	var op string
+	for _, tok := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(tok) {
+			op = tok
+			break
+		}
+	}
+	if op == "" {
+		p.pos = start
+		return x, nil
+	}
+	p.skipBlank()
+	y, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	a, err := p.valueExpr(x)
+	if err != nil {
+		return nil, err
+	}
+	b, err := p.valueExpr(y)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonPathComparison{op: op, a: a, b: b}, nil
+}
+
+func (p *jsonPathParser) parseParen() (jsonPathLogical, error) {
+	p.pos++ // '('
+	p.skipBlank()
+	x, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if !p.consume(")") {
+		return nil, p.errorf("expected )")
+	}
+	return p.logical(x)
+}
+
+// parsePrimary parses a literal, query or function expression.
+func (p *jsonPathParser) parsePrimary() (any, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		q := &jsonPathQuery{relative: c == '@'}
+		if err := p.parseSegments(q); err != nil {
+			return nil, err
+		}
+		return q, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathLiteral{str(s)}, nil
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case p.consume("true"):
+		return jsonPathLiteral{boolean(true)}, nil
+	case p.consume("false"):
+		return jsonPathLiteral{boolean(false)}, nil
+	case p.consume("null"):
+		return jsonPathLiteral{null{}}, nil
+	case 'a' <= c && c <= 'z':
+		return p.parseCall()
+	}
+	return nil, p.errorf("expected filter expression")
+}
+
+// parseNumber parses a JSON number literal which may also be -0.
+func (p *jsonPathParser) parseNumber() (any, error) {
+	start := p.pos
+	p.consume("-")
+	switch {
+	case p.consume("0"):
+		if isDigit(p.peek()) {
+			return nil, p.errorf("invalid number with leading zero")
+		}
+	case isDigit(p.peek()):
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	default:
+		return nil, p.errorf("expected number")
+	}
+	if p.consume(".") {
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("expected fraction digits")
+		}
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	if p.consume("e") || p.consume("E") {
+		if !p.consume("-") {
+			p.consume("+")
+		}
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("expected exponent digits")
+		}
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return nil, p.errorf("number %s out of range", p.s[start:p.pos])
+	}
+	return jsonPathLiteral{num(f)}, nil
+}
+
+func (p *jsonPathParser) parseCall() (any, error) {
+	start := p.pos
+	for c := p.peek(); 'a' <= c && c <= 'z' || c == '_' || isDigit(c); c = p.peek() {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	fn, ok := jsonPathFunctions[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %s", name)
+	}
+	if !p.consume("(") {
+		return nil, p.errorf("expected (")
+	}
+	p.skipBlank()
+	var args []any
+	if !p.consume(")") {
+		for {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, x)
+			p.skipBlank()
+			if p.consume(")") {
+				break
+			}
+			if !p.consume(",") {
+				return nil, p.errorf("expected , or )")
+			}
+			p.skipBlank()
+		}
+	}
+	if len(args) != len(fn.params) {
+		return nil, p.errorf("function %s() takes %d arguments", name, len(fn.params))
+	}
+	for i, arg := range args {
+		var err error
+		switch fn.params[i] {
+		case jsonPathValueType:
+			args[i], err = p.valueExpr(arg)
+		case jsonPathLogicalType:
+			args[i], err = p.logical(arg)
+		case jsonPathNodesType:
+			if _, ok := arg.(*jsonPathQuery); !ok {
+				err = p.errorf("function %s() argument %d is not a query", name, i+1)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if name == "match" || name == "search" {
+		if lit, ok := args[1].(jsonPathLiteral); ok {
+			if re, ok := lit.v.(str); ok {
+				// Compile literal patterns once with the query.
+				args[1] = jsonPathPattern{lit, compileIRegexp(string(re), name == "match")}
+			}
+		}
+	}
+	return &jsonPathCall{name: name, fn: fn, args: args}, nil
+}
+
+// logical converts the parsed expression x to a logical expression.
+// Queries test whether they select any node.
+func (p *jsonPathParser) logical(x any) (jsonPathLogical, error) {
+	switch x := x.(type) {
+	case *jsonPathQuery:
+		return jsonPathExists{x}, nil
+	case *jsonPathCall:
+		if x.fn.result == jsonPathValueType {
+			return nil, p.errorf("function %s() result is not logical", x.name)
+		}
+		return x, nil
+	case jsonPathLogical:
+		return x, nil
+	}
+	return nil, p.errorf("literal is not a logical expression")
+}
+
+// valueExpr converts the parsed expression x to a value expression.
+// Queries must be singular.
+func (p *jsonPathParser) valueExpr(x any) (jsonPathValueExpr, error) {
+	switch x := x.(type) {
+	case *jsonPathQuery:
+		if !x.singular() {
+			return nil, p.errorf("query is not singular")
+		}
+		return jsonPathSingular{x}, nil
+	case *jsonPathCall:
+		if x.fn.result != jsonPathValueType {
+			return nil, p.errorf("function %s() result is not a value", x.name)
+		}
+		return x, nil
+	case jsonPathLiteral:
+		return x, nil
+	}
+	return nil, p.errorf("logical expression is not a value")
+}
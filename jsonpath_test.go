@@ -0,0 +1,349 @@
+package jsong
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const jsonPathBookstore = `{"store":{"book":[` +
+	`{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},` +
+	`{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},` +
+	`{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},` +
+	`{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}` +
+	`],"bicycle":{"color":"red","price":399}}}`
+
+// queryString returns the values selected by the query in input as a JSON array.
+func queryString(t *testing.T, query string, input any) string {
+	t.Helper()
+	jp, err := CompileJSONPath(query)
+	if err != nil {
+		t.Fatalf("CompileJSONPath(%q): got err = %v, want err = false", query, err)
+	}
+	vs := jp.Values(input)
+	if vs == nil {
+		vs = []any{}
+	}
+	return encodeString(t, EncoderOptions{}, array(vs))
+}
+
+func TestJSONPathBookstore(t *testing.T) {
+	v := decodeOrdered(t, jsonPathBookstore)
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: "$.store.book[*].author", want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{query: "$..author", want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{query: "$.store..price", want: `[8.95,12.99,8.99,22.99,399]`},
+		{query: "$..book[2].author", want: `["Herman Melville"]`},
+		{query: "$..book[2].publisher", want: `[]`},
+		{query: "$..book[-1].title", want: `["The Lord of the Rings"]`},
+		{query: "$..book[0,1].title", want: `["Sayings of the Century","Sword of Honour"]`},
+		{query: "$..book[:2].title", want: `["Sayings of the Century","Sword of Honour"]`},
+		{query: "$..book[?@.isbn].title", want: `["Moby Dick","The Lord of the Rings"]`},
+		{query: "$..book[?@.price<10].title", want: `["Sayings of the Century","Moby Dick"]`},
+		{query: `$.store.book[?@.price < 10 && @.category == 'fiction'].author`, want: `["Herman Melville"]`},
+		{query: `$["store"]['bicycle']["color"]`, want: `["red"]`},
+		{query: "$.store.*.color", want: `["red"]`},
+		{query: "$..*.color", want: `["red"]`},
+		{query: "$.store.book[?length(@.title) > 15].title", want: `["Sayings of the Century","The Lord of the Rings"]`},
+		{query: "$.store.book[?!@.isbn].title", want: `["Sayings of the Century","Sword of Honour"]`},
+		{query: "$.store[?@.color].price", want: `[399]`},
+	} {
+		if diff := cmp.Diff(tc.want+"\n", queryString(t, tc.query, v)); diff != "" {
+			t.Errorf("Query(%q): got diff:\n%s", tc.query, diff)
+		}
+	}
+}
+
+func TestJSONPathSelectors(t *testing.T) {
+	v := decodeOrdered(t, `{"o":{"j j":{"k.k":3},"'":{"@":2}},"a":["a","b","c","d","e","f","g"]}`)
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: "$", want: `[{"o":{"j j":{"k.k":3},"'":{"@":2}},"a":["a","b","c","d","e","f","g"]}]`},
+		{query: `$.o['j j']['k.k']`, want: `[3]`},
+		{query: `$.o["j j"]["k.k"]`, want: `[3]`},
+		{query: `$["o"]["'"]["@"]`, want: `[2]`},
+		{query: `$.o['\'']`, want: `[{"@":2}]`},
+		{query: "$.a[1]", want: `["b"]`},
+		{query: "$.a[-2]", want: `["f"]`},
+		{query: "$.a[7]", want: `[]`},
+		{query: "$.a[-8]", want: `[]`},
+		{query: "$.a[1:3]", want: `["b","c"]`},
+		{query: "$.a[5:]", want: `["f","g"]`},
+		{query: "$.a[1:5:2]", want: `["b","d"]`},
+		{query: "$.a[5:1:-2]", want: `["f","d"]`},
+		{query: "$.a[::-1]", want: `["g","f","e","d","c","b","a"]`},
+		{query: "$.a[::0]", want: `[]`},
+		{query: "$.a[-100:100]", want: `["a","b","c","d","e","f","g"]`},
+		{query: "$.a[0, 0]", want: `["a","a"]`},
+		{query: "$.a[ 0 : 2 : 1 ]", want: `["a","b"]`},
+		{query: "$.o[0]", want: `[]`},
+		{query: "$.a.b", want: `[]`},
+		{query: "$.a.*", want: `["a","b","c","d","e","f","g"]`},
+		{query: "$ .a [0]", want: `["a"]`},
+	} {
+		if diff := cmp.Diff(tc.want+"\n", queryString(t, tc.query, v)); diff != "" {
+			t.Errorf("Query(%q): got diff:\n%s", tc.query, diff)
+		}
+	}
+}
+
+func TestJSONPathDescendants(t *testing.T) {
+	v := decodeOrdered(t, `{"o":{"j":1,"k":2},"a":[5,3,[{"j":4},{"k":6}]]}`)
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: "$..j", want: `[1,4]`},
+		{query: "$..[0]", want: `[5,{"j":4}]`},
+		{query: "$..*", want: `[{"j":1,"k":2},[5,3,[{"j":4},{"k":6}]],1,2,5,3,[{"j":4},{"k":6}],{"j":4},{"k":6},4,6]`},
+		{query: "$..o[?@ > 1]", want: `[2]`},
+		{query: "$.a..[?@.k]", want: `[{"k":6}]`},
+	} {
+		if diff := cmp.Diff(tc.want+"\n", queryString(t, tc.query, v)); diff != "" {
+			t.Errorf("Query(%q): got diff:\n%s", tc.query, diff)
+		}
+	}
+}
+
+func TestJSONPathComparisons(t *testing.T) {
+	// The comparison examples of RFC 9535 Table 11.
+	v := decodeOrdered(t, `{"obj":{"x":"y"},"arr":[2,3]}`)
+
+	for _, tc := range []struct {
+		expr string
+		want bool
+	}{
+		{expr: "$.absent1 == $.absent2", want: true},
+		{expr: "$.absent1 <= $.absent2", want: true},
+		{expr: "$.absent == 'g'", want: false},
+		{expr: "$.absent1 != $.absent2", want: false},
+		{expr: "$.absent != 'g'", want: true},
+		{expr: "1 <= 2", want: true},
+		{expr: "1 > 2", want: false},
+		{expr: "13 == '13'", want: false},
+		{expr: "'a' <= 'b'", want: true},
+		{expr: "'a' > 'b'", want: false},
+		{expr: "$.obj == $.arr", want: false},
+		{expr: "$.obj != $.arr", want: true},
+		{expr: "$.obj == $.obj", want: true},
+		{expr: "$.obj != $.obj", want: false},
+		{expr: "$.arr == $.arr", want: true},
+		{expr: "$.arr != $.arr", want: false},
+		{expr: "$.obj == 17", want: false},
+		{expr: "$.obj != 17", want: true},
+		{expr: "$.obj <= $.arr", want: false},
+		{expr: "$.obj < $.arr", want: false},
+		{expr: "$.obj <= $.obj", want: true},
+		{expr: "$.arr <= $.arr", want: true},
+		{expr: "1 <= $.arr", want: false},
+		{expr: "1 >= $.arr", want: false},
+		{expr: "1 > $.arr", want: false},
+		{expr: "1 < $.arr", want: false},
+		{expr: "true <= true", want: true},
+		{expr: "true > true", want: false},
+		{expr: "1 == 1.0", want: true},
+		{expr: "1e2 == 100", want: true},
+		{expr: "null == null", want: true},
+		{expr: "$.arr[0] < $.arr[1] || false == true", want: true},
+		{expr: "!($.arr[0] < $.arr[1]) && 1 == 1", want: false},
+	} {
+		jp := Must(CompileJSONPath("$[?" + tc.expr + "]"))
+
+		if got := len(jp.Query(v)) == 2; got != tc.want {
+			t.Errorf("Query(%q): got %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestJSONPathFunctions(t *testing.T) {
+	v := decodeOrdered(t, `[{"s":"abc","a":[1,2]},{"s":"aé","o":{"x":1}},{"s":"x\ny","a":[]},{"s":7}]`)
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: "$[?length(@.s) == 3].s", want: `["abc","x\ny"]`},
+		{query: "$[?length(@.s) == 2].s", want: `["aé"]`},
+		{query: "$[?length(@.a) == 2].s", want: `["abc"]`},
+		{query: "$[?length(@.o) == 1].s", want: `["aé"]`},
+		{query: "$[?length(@.s) == 1].s", want: `[]`},
+		{query: "$[?count(@.*) == 1].s", want: `[7]`},
+		{query: "$[?count(@..*) > 3].s", want: `["abc"]`},
+		{query: "$[?match(@.s, 'a.')].s", want: `["aé"]`},
+		{query: "$[?match(@.s, 'x.y')].s", want: `[]`},
+		{query: "$[?search(@.s, 'b')].s", want: `["abc"]`},
+		{query: "$[?search(@.s, '[a-b]+')].s", want: `["abc","aé"]`},
+		{query: "$[?match(@.s, '(')].s", want: `[]`},
+		{query: "$[?value(@..x) == 1].s", want: `["aé"]`},
+		{query: "$[?value(@.*) == 7].s", want: `[7]`},
+	} {
+		if diff := cmp.Diff(tc.want+"\n", queryString(t, tc.query, v)); diff != "" {
+			t.Errorf("Query(%q): got diff:\n%s", tc.query, diff)
+		}
+	}
+}
+
+func TestJSONPathDynamicPatterns(t *testing.T) {
+	v := decodeOrdered(t, `[{"s":"abc","re":"a.c"},{"s":"abc","re":"b"},{"s":"abc","re":"("},{"s":"abc","re":1}]`)
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: "$[?match(@.s, @.re)].re", want: `["a.c"]`},
+		{query: "$[?search(@.s, @.re)].re", want: `["a.c","b"]`},
+		{query: "$[?search(@.s, 'b')].re", want: `["a.c","b","(",1]`},
+	} {
+		if diff := cmp.Diff(tc.want+"\n", queryString(t, tc.query, v)); diff != "" {
+			t.Errorf("Query(%q): got diff:\n%s", tc.query, diff)
+		}
+	}
+}
+
+func TestCompileJSONPathPatterns(t *testing.T) {
+	jp := Must(CompileJSONPath("$[?match(@.s, 'a.') && search(@.s, '(')]"))
+
+	var patterns []jsonPathPattern
+	var walk func(x any)
+	walk = func(x any) {
+		switch x := x.(type) {
+		case jsonPathAnd:
+			for _, e := range x {
+				walk(e)
+			}
+		case *jsonPathCall:
+			if p, ok := x.args[1].(jsonPathPattern); ok {
+				patterns = append(patterns, p)
+			}
+		}
+	}
+	walk(jp.q.segments[0].selectors[0].(jsonPathFilter).expr)
+
+	if len(patterns) != 2 {
+		t.Fatalf("CompileJSONPath(): got %d compiled patterns, want 2", len(patterns))
+	}
+	if got, want := patterns[0].re.String(), "^(?:a[^\\n\\r])$"; got != want {
+		t.Errorf("CompileJSONPath(): got match pattern %q, want %q", got, want)
+	}
+	if patterns[1].re != nil {
+		t.Errorf("CompileJSONPath(): got search pattern %v for an invalid pattern, want nil", patterns[1].re)
+	}
+}
+
+func TestJSONPathInvalid(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"a",
+		"$.",
+		"$..",
+		" $",
+		"$ ",
+		"$.1",
+		"$. a",
+		"$[",
+		"$[]",
+		"$[0",
+		"$[01]",
+		"$[-0]",
+		"$[9007199254740992]",
+		"$[1:2:3:4]",
+		"$['a]",
+		`$['\"']`,
+		`$["\'"]`,
+		`$['\ud800']`,
+		`$['\q']`,
+		"$['\x01']",
+		"$[?@.a == 1 == 2]",
+		"$[?1]",
+		"$[?@.* == 1]",
+		"$[?@..a == 1]",
+		"$[?length(@.*) == 1]",
+		"$[?length(@.a)]",
+		"$[?count(1) == 1]",
+		"$[?match(@.a) == 1]",
+		"$[?match(@.a, 'a') == true]",
+		"$[?foo(@.a)]",
+		"$[?(@.a]",
+		"$[?@.a == 01]",
+		"$[?@.a == 1.]",
+		"$[?!1]",
+		"$[?@.a && true]",
+	} {
+		if _, err := CompileJSONPath(query); err == nil {
+			t.Errorf("CompileJSONPath(%q): got err = false, want err = true", query)
+		}
+	}
+}
+
+func TestJSONPathNodes(t *testing.T) {
+	v := object{
+		"a": array{num(1), object{"b'c": num(2), "\n\x01\\": num(3)}},
+	}
+	jp := Must(CompileJSONPath("$.a..*"))
+
+	got := jp.Query(v)
+
+	want := []JSONPathNode{
+		{Path: Path{"a", int64(0)}, Value: num(1)},
+		{Path: Path{"a", int64(1)}, Value: object{"b'c": num(2), "\n\x01\\": num(3)}},
+		{Path: Path{"a", int64(1), "\n\x01\\"}, Value: num(3)},
+		{Path: Path{"a", int64(1), "b'c"}, Value: num(2)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Query(): got diff:\n%s", diff)
+	}
+
+	var paths []string
+	for _, n := range got {
+		paths = append(paths, n.Path.JSONPath())
+	}
+	wantPaths := []string{
+		"$['a'][0]",
+		"$['a'][1]",
+		`$['a'][1]['\n\u0001\\']`,
+		`$['a'][1]['b\'c']`,
+	}
+	if diff := cmp.Diff(wantPaths, paths); diff != "" {
+		t.Errorf("JSONPath(): got diff:\n%s", diff)
+	}
+
+	for _, n := range got {
+		if got := Must(CompileJSONPath(n.Path.JSONPath())).Values(v); len(got) != 1 || Compare(got[0], n.Value) != 0 {
+			t.Errorf("Query(%q): got %v, want %v", n.Path.JSONPath(), got, n.Value)
+		}
+	}
+}
+
+func TestJSONPathNil(t *testing.T) {
+	v := array{nil, object{"a": nil}}
+
+	if diff := cmp.Diff(`[null,null]`+"\n", queryString(t, "$..[?@ == null]", v)); diff != "" {
+		t.Errorf("Query(): got diff:\n%s", diff)
+	}
+}
+
+func FuzzCompileJSONPath(f *testing.F) {
+	for _, seed := range []string{"$", "$.a[0]", "$..b[?@.c > 1 && match(@.d, 'e.*')]", "$[1:2:-1]", `$['é']`} {
+		f.Add(seed)
+	}
+	v := decodeOrdered(f, jsonPathBookstore)
+	f.Fuzz(func(t *testing.T, query string) {
+		jp, err := CompileJSONPath(query)
+		if err != nil {
+			return
+		}
+		for _, n := range jp.Query(v) {
+			if got := Extract(v, n.Path.String()); Compare(got, n.Value) != 0 {
+				t.Errorf("Query(%q): got %v at %s, want %v", query, n.Value, n.Path.JSONPath(), got)
+			}
+		}
+	})
+}
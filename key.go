@@ -13,23 +13,77 @@ const reserved = ".*"
 
 func quoteHint(k string) bool { return strings.HasPrefix(k, `"`) }
 
+// needsQuote reports whether JoinKey must quote the key k
+// for CutKey to return it as is and paths beginning with k
+// are not read as JSON Pointers.
+func needsQuote(k string) bool {
+	if k == "" || quoteHint(k) || pointerHint(k) || indexHint(k) || strings.ContainsAny(k, reserved) {
+		return true
+	}
+	_, isSlice := parseSlice(k)
+	return isSlice
+}
+
+// CutKey cuts the first segment from the path k and returns it as head
+// with the rest of the path in tail. It reports leaf if head is the last
+// segment of k.
+//
+// Segments are separated by dots. A segment is either a double quoted Go
+// string literal, which may contain dots and escapes, or the text up to
+// the next dot. Unquoted decimal integers are returned as int64 indices,
+// where negative indices count from the end of an array, unquoted slices
+// like "1:3" as Slice and other segments as strings. A segment which
+// starts with a quote but is not a valid string literal followed by a dot
+// or the end of k is read as an unquoted segment.
+//
+// Unquoted segments like "-1" are always index segments and unquoted
+// segments like "1:3", ":2" or "-2:" are always slice segments, also
+// where the value is an object, so they never select object members.
+// JoinKey quotes keys of these forms and paths to such keys must quote
+// them like `"-1"`.
 func CutKey(k string) (head any, tail string, leaf bool) {
-	if quoteHint(k) {
-		panic("CutKey: unquote is not implemented yet!")
+	s, quoted, tail, leaf := cutSegment(k)
+	if quoted {
+		return s, tail, leaf
 	}
-	s, tail, found := strings.Cut(k, string(dot))
-	leaf = !found
 	if i, ok := index(s); ok {
 		return i, tail, leaf
 	}
+	if sl, ok := parseSlice(s); ok {
+		return sl, tail, leaf
+	}
 	return s, tail, leaf
 }
 
+// cutSegment cuts the first segment from the path k like CutKey
+// and returns its text, which is unquoted if quoted is set.
+func cutSegment(k string) (seg string, quoted bool, tail string, leaf bool) {
+	if quoteHint(k) {
+		if q, err := strconv.QuotedPrefix(k); err == nil {
+			rest := k[len(q):]
+			if rest == "" || rest[0] == byte(dot) {
+				s, _ := strconv.Unquote(q)
+				if rest == "" {
+					return s, true, "", true
+				}
+				return s, true, rest[1:], false
+			}
+		}
+	}
+	seg, tail, found := strings.Cut(k, string(dot))
+	return seg, false, tail, !found
+}
+
+// IsLeaf reports whether the path k has a single segment.
 func IsLeaf(k string) bool {
-	return !strings.ContainsRune(k, dot)
+	_, _, leaf := CutKey(k)
+	return leaf
 }
 
 func indexHint(k string) bool {
+	if strings.HasPrefix(k, "-") {
+		k = k[1:]
+	}
 	return len(k) > 0 && '0' <= k[0] && k[0] <= '9'
 }
 
@@ -44,8 +98,11 @@ func index(k string) (int64, bool) {
 
 // JoinKey appends the key args to the base.
 //
-// Args should be either string or int64
-// or else JoinKey panics.
+// Args should be either string, int64 or Slice
+// or else JoinKey panics. String keys which are empty,
+// contain reserved characters or would be read as an index,
+// a slice, a quoted segment or a JSON Pointer are quoted so
+// CutKey returns them as is.
 func JoinKey(base string, as ...any) string {
 	var sb strings.Builder
 	sb.WriteString(base)
@@ -56,8 +113,10 @@ func JoinKey(base string, as ...any) string {
 		switch a := a.(type) {
 		case int64:
 			fmt.Fprint(&sb, a)
+		case Slice:
+			sb.WriteString(a.String())
 		case string:
-			if indexHint(a) || strings.ContainsAny(a, reserved) {
+			if needsQuote(a) {
 				sb.WriteString(strconv.Quote(a))
 				continue
 			}
@@ -69,19 +128,143 @@ func JoinKey(base string, as ...any) string {
 	return sb.String()
 }
 
-type KeyMatcher struct{ r *regexp.Regexp }
+type KeyMatcher struct {
+	r    *regexp.Regexp
+	segs []globSegment // Glob segments used to match key prefixes.
+	rel  []any         // Negative indices and slices in the order of the submatches of r.
+}
+
+// globSegment is a segment of a glob. Quoted segments hold
+// their key as written by JoinKey and have no stars.
+type globSegment struct {
+	glob   string
+	quoted bool
+}
 
+// CompileKeyMatcher compiles the glob into a KeyMatcher.
+//
+// Globs are split into segments like paths by CutKey. Quoted segments
+// match their key literally. Negative index and slice segments match
+// any index since the length of arrays is not known from keys alone.
+// Glob resolves them against the value.
 func CompileKeyMatcher(glob string) (*KeyMatcher, error) {
-	glob = regexp.QuoteMeta(glob)
-	glob = strings.ReplaceAll(glob, `\*\*`, ".*")
-	glob = strings.ReplaceAll(glob, `\*`, "[^.]*")
-	r, err := regexp.Compile(fmt.Sprint("^", glob, "$"))
+	var segs []globSegment
+	for rest, leaf := glob, glob == ""; !leaf; {
+		var seg string
+		var quoted bool
+		seg, quoted, rest, leaf = cutSegment(rest)
+		if quoted {
+			seg = JoinKey("", seg)
+		}
+		segs = append(segs, globSegment{glob: seg, quoted: quoted})
+	}
+	var rel []any
+	patterns := make([]string, len(segs))
+	for i, seg := range segs {
+		if seg.quoted {
+			patterns[i] = regexp.QuoteMeta(seg.glob)
+			continue
+		}
+		if k, ok := relativeIndex(seg.glob); ok {
+			rel = append(rel, k)
+			patterns[i] = `([0-9]+)`
+			continue
+		}
+		pattern := regexp.QuoteMeta(seg.glob)
+		pattern = strings.ReplaceAll(pattern, `\*\*`, ".*")
+		patterns[i] = strings.ReplaceAll(pattern, `\*`, "[^.]*")
+	}
+	pattern := strings.Join(patterns, `\.`)
+	if n := len(segs); n > 1 && !segs[n-1].quoted && segs[n-1].glob == doubleStar {
+		// A trailing double star also matches the parent itself.
+		pattern = strings.Join(patterns[:n-1], `\.`) + `(?:\..*)?`
+	}
+	r, err := regexp.Compile(fmt.Sprint("^", pattern, "$"))
 	if err != nil {
 		return nil, err
 	}
-	return &KeyMatcher{r: r}, nil
+	return &KeyMatcher{r: r, segs: segs, rel: rel}, nil
 }
 
 func (m *KeyMatcher) MatchKey(k string) bool {
 	return m.r.MatchString(k)
 }
+
+// matchKeyLen is like MatchKey but resolves negative indices and
+// slices with the lengths of the arrays by their keys in lens.
+func (m *KeyMatcher) matchKeyLen(k string, lens map[string]int) bool {
+	if len(m.rel) == 0 {
+		return m.MatchKey(k)
+	}
+	sub := m.r.FindStringSubmatchIndex(k)
+	if sub == nil {
+		return false
+	}
+	for j, rel := range m.rel {
+		start, end := sub[2*j+2], sub[2*j+3]
+		var parent string
+		if start > 0 {
+			parent = k[:start-1]
+		}
+		n, ok := lens[parent]
+		if !ok {
+			return false
+		}
+		i, err := strconv.ParseInt(k[start:end], 10, 64)
+		if err != nil || !selectsIndex(rel, i, n) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPrefix reports whether any key beginning with
+// the key segments in prefix could match.
+//
+// It may report false positives for globs containing a double star.
+func (m *KeyMatcher) matchPrefix(prefix []any) bool {
+	for i, seg := range prefix {
+		if i >= len(m.segs) {
+			return false
+		}
+		glob := m.segs[i]
+		if glob.quoted {
+			if glob.glob != JoinKey("", seg) {
+				return false
+			}
+			continue
+		}
+		if strings.Contains(glob.glob, doubleStar) {
+			return true
+		}
+		if _, ok := relativeIndex(glob.glob); ok {
+			if _, isIndex := seg.(int64); !isIndex {
+				return false
+			}
+			continue
+		}
+		if !matchSegment(glob.glob, JoinKey("", seg)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchSegment reports whether the key segment s matches glob
+// where each star matches any run of characters.
+func matchSegment(glob, s string) bool {
+	before, after, found := strings.Cut(glob, string(star))
+	if !found {
+		return glob == s
+	}
+	if !strings.HasPrefix(s, before) {
+		return false
+	}
+	s = s[len(before):]
+	for i := 0; i <= len(s); i++ {
+		if matchSegment(after, s[i:]) {
+			return true
+		}
+	}
+	return false
+}
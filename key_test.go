@@ -1,6 +1,7 @@
 package jsong
 
 import (
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -19,7 +20,17 @@ func TestJoinKey(t *testing.T) {
 func TestJoinKeyQuoteReserved(t *testing.T) {
 	got := JoinKey("foo.bar", `.`, "*", "")
 
-	want := `foo.bar."."."*".`
+	want := `foo.bar."."."*".""`
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("JoinKey(): got diff:\n%s", diff)
+	}
+}
+
+func TestJoinKeyQuoteIndexAndSlice(t *testing.T) {
+	got := JoinKey("a", "-1", "1:3", ":", "-", "a:b", int64(-1), Slice{1, 3}, Slice{-2, math.MaxInt64})
+
+	want := `a."-1"."1:3".":".-.a:b.-1.1:3.-2:`
 
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Errorf("JoinKey(): got diff:\n%s", diff)
@@ -61,3 +72,137 @@ func TestCompileKeyMatcherDoubleStar(t *testing.T) {
 		t.Errorf("CompileKeyMatcher(): got diff:\n%s", diff)
 	}
 }
+
+func TestCompileKeyMatcherTrailingDoubleStar(t *testing.T) {
+	m := Must(CompileKeyMatcher("a.**"))
+
+	for _, tc := range []struct {
+		key  string
+		want bool
+	}{
+		{key: "a", want: true},
+		{key: "a.b", want: true},
+		{key: "a.b.c", want: true},
+		{key: "ab"},
+		{key: "b.a"},
+	} {
+		if got := m.MatchKey(tc.key); got != tc.want {
+			t.Errorf("MatchKey(%q): got %v, want %v", tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestCutKey(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		wantHead any
+		wantTail string
+		wantLeaf bool
+	}{
+		{input: "", wantHead: "", wantLeaf: true},
+		{input: "a", wantHead: "a", wantLeaf: true},
+		{input: "a.b.c", wantHead: "a", wantTail: "b.c"},
+		{input: "12.a", wantHead: int64(12), wantTail: "a"},
+		{input: "1x", wantHead: "1x", wantLeaf: true},
+		{input: `"12".a`, wantHead: "12", wantTail: "a"},
+		{input: `"a.b".c`, wantHead: "a.b", wantTail: "c"},
+		{input: `"a\"b\\.c"`, wantHead: `a"b\.c`, wantLeaf: true},
+		{input: `"é\x00\n"`, wantHead: "é\x00\n", wantLeaf: true},
+		{input: `"".a`, wantHead: "", wantTail: "a"},
+		{input: `"a"`, wantHead: "a", wantLeaf: true},
+		{input: `"a".`, wantHead: "a", wantTail: ""},
+		{input: `"a"b.c`, wantHead: `"a"b`, wantTail: "c"},
+		{input: `"a.b`, wantHead: `"a`, wantTail: "b"},
+		{input: `"\q".a`, wantHead: `"\q"`, wantTail: "a"},
+		{input: "-1.a", wantHead: int64(-1), wantTail: "a"},
+		{input: "-", wantHead: "-", wantLeaf: true},
+		{input: "-x", wantHead: "-x", wantLeaf: true},
+		{input: "1:3.a", wantHead: Slice{1, 3}, wantTail: "a"},
+		{input: "-2:", wantHead: Slice{-2, math.MaxInt64}, wantLeaf: true},
+		{input: ":-1", wantHead: Slice{0, -1}, wantLeaf: true},
+		{input: ":", wantHead: Slice{0, math.MaxInt64}, wantLeaf: true},
+		{input: "1:2:3", wantHead: "1:2:3", wantLeaf: true},
+		{input: "a:b", wantHead: "a:b", wantLeaf: true},
+		{input: `"1:3"`, wantHead: "1:3", wantLeaf: true},
+	} {
+		head, tail, leaf := CutKey(tc.input)
+		if head != tc.wantHead || tail != tc.wantTail || leaf != tc.wantLeaf {
+			t.Errorf("CutKey(%q): got %#v, %q, %v, want %#v, %q, %v", tc.input, head, tail, leaf, tc.wantHead, tc.wantTail, tc.wantLeaf)
+		}
+	}
+}
+
+func TestVisitPathsExtract(t *testing.T) {
+	v := object{
+		"":      object{"": num(1)},
+		"a.b":   array{object{"*": num(2)}},
+		"0":     num(3),
+		`"q"`:   num(4),
+		"\xff":  num(5),
+		"a\\.b": num(6),
+	}
+
+	Visit(v, func(k string, want any) error {
+		if got := Extract(v, k); Compare(got, want) != 0 {
+			t.Errorf("Extract(%q): got %v, want %v", k, got, want)
+		}
+		return nil
+	})
+}
+
+// cutKeys returns the segments of the path k.
+func cutKeys(k string) []any {
+	var segs []any
+	for {
+		head, tail, leaf := CutKey(k)
+		segs = append(segs, head)
+		if leaf {
+			return segs
+		}
+		k = tail
+	}
+}
+
+func FuzzJoinKeyCutKey(f *testing.F) {
+	for _, seed := range []struct{ a, b string }{
+		{"a", "b"},
+		{"", ""},
+		{".", "*"},
+		{"0", "-1"},
+		{`"`, `"a".b`},
+		{"\\", "\xff"},
+		{"a b", " "},
+		{"/a", "~1"},
+		{"-2", "1:"},
+	} {
+		f.Add(seed.a, seed.b, int64(0))
+	}
+	f.Fuzz(func(t *testing.T, a, b string, i int64) {
+		want := []any{a, b}
+		if i >= 0 {
+			want = append(want, i)
+		}
+		path := JoinKey("", want...)
+
+		got := cutKeys(path)
+
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("CutKey(JoinKey(%q)) = %q: got diff:\n%s", want, path, diff)
+		}
+	})
+}
+
+func FuzzCutKey(f *testing.F) {
+	for _, seed := range []string{"", "a.b", `"a.b".c`, `"a`, `"\q".`, `""."".`, "0.1"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, path string) {
+		_, tail, leaf := CutKey(path)
+		if leaf && tail != "" {
+			t.Errorf("CutKey(%q): got leaf with tail %q", path, tail)
+		}
+		if len(tail) >= len(path) && path != "" {
+			t.Errorf("CutKey(%q): got tail %q, want shorter", path, tail)
+		}
+	})
+}
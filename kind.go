@@ -0,0 +1,249 @@
+package jsong
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"golang.org/x/exp/maps"
+)
+
+// Kind is the kind of a jsong value.
+type Kind uint8
+
+const (
+	KindInvalid Kind = iota
+	KindNull
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+var kindNames = [...]string{
+	KindInvalid: "invalid",
+	KindNull:    "null",
+	KindBool:    "bool",
+	KindNumber:  "number",
+	KindString:  "string",
+	KindArray:   "array",
+	KindObject:  "object",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// KindOf returns the kind of the jsong value v.
+// A nil v is null and Go values which are not
+// jsong values are invalid.
+func KindOf(v any) Kind {
+	switch v := v.(type) {
+	case nil, null:
+		return KindNull
+	case boolean:
+		return KindBool
+	case num, decimal:
+		return KindNumber
+	case str:
+		return KindString
+	case array, *persistentArray:
+		return KindArray
+	case object, *orderedObject, *persistentObject:
+		return KindObject
+	case Value:
+		return v.Kind()
+	default:
+		return KindInvalid
+	}
+}
+
+// Value is a read-only view of a jsong value for inspecting
+// jsong trees without type switches. The zero Value is invalid.
+type Value struct {
+	v valueInterface
+}
+
+// View returns the Value of v.
+// Go values are converted with ValueOf. Values which
+// ValueOf cannot convert return an invalid Value.
+func View(v any) Value {
+	switch v := v.(type) {
+	case nil:
+		return Value{null{}}
+	case Value:
+		return v
+	case valueInterface:
+		return Value{v}
+	default:
+		return Value{valueOf(v)}
+	}
+}
+
+// Interface returns the jsong value of v or nil if v is invalid.
+func (v Value) Interface() any {
+	if v.v == nil {
+		return nil
+	}
+	return v.v
+}
+
+// JSONGValue returns the jsong value of v for ValueOf.
+func (v Value) JSONGValue() any { return v.Interface() }
+
+// MarshalJSON writes the jsong value of v.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return new(Encoder).appendValue(nil, v.v, 0)
+}
+
+// IsValid reports whether v is a value.
+func (v Value) IsValid() bool { return v.v != nil }
+
+// Kind returns the kind of v.
+func (v Value) Kind() Kind {
+	if v.v == nil {
+		return KindInvalid
+	}
+	return KindOf(v.v)
+}
+
+// Len returns the number of elements of an array, entries of an object
+// or bytes of a string. It returns 0 for other kinds.
+func (v Value) Len() int {
+	switch v := v.v.(type) {
+	case str:
+		return len(v)
+	case array:
+		return len(v)
+	case object:
+		return len(v)
+	case *orderedObject:
+		return v.Len()
+	case *persistentArray:
+		return v.Len()
+	case *persistentObject:
+		return v.Len()
+	default:
+		return 0
+	}
+}
+
+// Index returns the element i of an array.
+// It returns an invalid Value if v is not an array
+// or i is out of range.
+func (v Value) Index(i int) Value {
+	switch a := v.v.(type) {
+	case array:
+		if i < 0 || i >= len(a) {
+			return Value{}
+		}
+		return View(a[i])
+	case *persistentArray:
+		if i < 0 || i >= a.Len() {
+			return Value{}
+		}
+		return View(a.get(i))
+	default:
+		return Value{}
+	}
+}
+
+// Field returns the value of the key k of an object.
+// It returns an invalid Value if v is not an object
+// or has no key k.
+func (v Value) Field(k string) Value {
+	if v.Kind() != KindObject {
+		return Value{}
+	}
+	e, ok := v.v.Get(k)
+	if !ok {
+		return Value{}
+	}
+	return View(e)
+}
+
+// Keys returns the keys of an object in insertion order for
+// ordered objects and sorted order otherwise.
+// It returns nil for other kinds.
+func (v Value) Keys() []string {
+	switch v := v.v.(type) {
+	case object:
+		return slices.Sorted(slices.Values(maps.Keys(v)))
+	case *orderedObject:
+		return slices.Clone(v.keys)
+	case *persistentObject:
+		keys := make([]string, 0, v.Len())
+		v.Each(func(k, _ any) bool {
+			keys = append(keys, k.(string))
+			return true
+		})
+		slices.Sort(keys)
+		return keys
+	default:
+		return nil
+	}
+}
+
+// As returns the jsong value v as a T.
+//
+// The types bool, float64, int64, uint64, int, string, []any,
+// map[string]any, Value and any use the accessor of the same name.
+// Other types are stored using Into. Go values are converted with
+// ValueOf first. It reports false if v cannot be stored in a T.
+func As[T any](v any) (T, bool) {
+	var res T
+	if w, ok := v.(Value); ok {
+		if !w.IsValid() {
+			return res, false
+		}
+		v = w.v
+	}
+	if _, ok := v.(valueInterface); !ok && v != nil {
+		var err error
+		if v, err = ValueOfErr(v); err != nil {
+			return res, false
+		}
+	}
+	ok := true
+	switch p := any(&res).(type) {
+	case *bool:
+		*p, ok = Bool(v)
+	case *float64:
+		*p, ok = Float64(v)
+	case *int64:
+		*p, ok = Int64(v)
+	case *uint64:
+		*p, ok = Uint64(v)
+	case *int:
+		var n int64
+		n, ok = Int64(v)
+		if ok && (n < math.MinInt || n > math.MaxInt) {
+			ok = false
+		}
+		*p = int(n)
+	case *string:
+		*p, ok = String(v)
+	case *[]any:
+		*p, ok = Array(v)
+	case *map[string]any:
+		*p, ok = Object(v)
+	case *Value:
+		*p = View(v)
+	case *any:
+		*p = v
+	default:
+		if v == nil {
+			v = null{}
+		}
+		ok = Into(v, &res) == nil
+	}
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return res, true
+}
@@ -0,0 +1,127 @@
+package jsong
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKindOf(t *testing.T) {
+	for _, tc := range []struct {
+		input any
+		want  Kind
+	}{
+		{input: nil, want: KindNull},
+		{input: null{}, want: KindNull},
+		{input: boolean(true), want: KindBool},
+		{input: num(1), want: KindNumber},
+		{input: decimal("1"), want: KindNumber},
+		{input: str("a"), want: KindString},
+		{input: array{}, want: KindArray},
+		{input: object{}, want: KindObject},
+		{input: newOrderedObject(0), want: KindObject},
+		{input: Value{}, want: KindInvalid},
+		{input: View(nil), want: KindNull},
+		{input: "a", want: KindInvalid},
+	} {
+		if got := KindOf(tc.input); got != tc.want {
+			t.Errorf("KindOf(%#v): got %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestValueView(t *testing.T) {
+	v := View(Must(DecoderOptions{OrderedObjects: true}.NewDecoder(strings.NewReader(
+		`{"b":[1,"two",null,{"x":true}],"a":"abc"}`)).Decode()))
+
+	if got := v.Kind(); got != KindObject {
+		t.Errorf("Kind(): got %v, want object", got)
+	}
+	if diff := cmp.Diff([]string{"b", "a"}, v.Keys()); diff != "" {
+		t.Errorf("Keys(): got diff:\n%s", diff)
+	}
+	b := v.Field("b")
+	if got := b.Len(); got != 4 {
+		t.Errorf("Len(): got %d, want 4", got)
+	}
+	for i, want := range []Kind{KindNumber, KindString, KindNull, KindObject, KindInvalid} {
+		if got := b.Index(i).Kind(); got != want {
+			t.Errorf("Index(%d).Kind(): got %v, want %v", i, got, want)
+		}
+	}
+	if got, ok := As[bool](b.Index(3).Field("x")); !ok || !got {
+		t.Errorf("As[bool](): got %v, %v, want true, true", got, ok)
+	}
+	if got := v.Field("a").Len(); got != 3 {
+		t.Errorf("Len(): got %d, want 3", got)
+	}
+	if got := v.Field("missing").Field("x").Index(0); got.IsValid() {
+		t.Errorf("Field(): got %v, want invalid", got)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, View(map[string]int{"b": 1, "a": 2}).Keys()); diff != "" {
+		t.Errorf("Keys(): got diff:\n%s", diff)
+	}
+	if got := v.Field("a").Keys(); got != nil {
+		t.Errorf("Keys(): got %v, want nil", got)
+	}
+}
+
+func TestValueViewJSON(t *testing.T) {
+	v := struct{ V Value }{View(map[string]any{"a": []any{1, nil}})}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal(): got err = %v, want err = false", err)
+	}
+	if got, want := string(data), `{"V":{"a":[1,null]}}`; got != want {
+		t.Errorf("json.Marshal(): got %s, want %s", got, want)
+	}
+	if diff := cmp.Diff(object{"V": object{"a": array{num(1), nil}}}, ValueOf(v)); diff != "" {
+		t.Errorf("ValueOf(): got diff:\n%s", diff)
+	}
+}
+
+func TestAs(t *testing.T) {
+	v := ValueOptions{ExactNumbers: true}.ValueOf(map[string]any{
+		"n": 1 << 40, "s": "abc", "a": []any{1, 2}, "f": 1.5,
+	})
+
+	if got, ok := As[int](Extract(v, "n")); !ok || got != 1<<40 {
+		t.Errorf("As[int](): got %v, %v, want %v, true", got, ok, 1<<40)
+	}
+	if got, ok := As[int](Extract(v, "f")); ok {
+		t.Errorf("As[int](): got %v, %v, want false", got, ok)
+	}
+	if got, ok := As[float64](Extract(v, "f")); !ok || got != 1.5 {
+		t.Errorf("As[float64](): got %v, %v, want 1.5, true", got, ok)
+	}
+	if got, ok := As[string](Extract(v, "s")); !ok || got != "abc" {
+		t.Errorf("As[string](): got %v, %v, want abc, true", got, ok)
+	}
+	if got, ok := As[string](Extract(v, "n")); ok {
+		t.Errorf("As[string](): got %v, %v, want false", got, ok)
+	}
+	if got, ok := As[[]int](Extract(v, "a")); !ok || !cmp.Equal(got, []int{1, 2}) {
+		t.Errorf("As[[]int](): got %v, %v, want [1 2], true", got, ok)
+	}
+	if got, ok := As[[]string](Extract(v, "a")); ok {
+		t.Errorf("As[[]string](): got %v, %v, want false", got, ok)
+	}
+	if got, ok := As[map[string]any](v); !ok || len(got) != 4 {
+		t.Errorf("As[map[string]any](): got %v, %v, want 4 entries", got, ok)
+	}
+	if got, ok := As[Value](v); !ok || got.Kind() != KindObject {
+		t.Errorf("As[Value](): got %v, %v, want object", got, ok)
+	}
+	if got, ok := As[*int](nil); !ok || got != nil {
+		t.Errorf("As[*int](): got %v, %v, want nil, true", got, ok)
+	}
+	if got, ok := As[string]("go"); !ok || got != "go" {
+		t.Errorf("As[string](): got %v, %v, want go, true", got, ok)
+	}
+	if _, ok := As[bool](Value{}); ok {
+		t.Errorf("As[bool](): got ok for invalid Value, want false")
+	}
+}
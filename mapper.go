@@ -19,14 +19,23 @@ func (a MapSeq) Map(v any) any {
 	return v
 }
 
+// MulScalar multiplies numbers by M.
+// Other values are returned unchanged.
 type MulScalar struct {
 	M any
 }
 
 func (a MulScalar) Map(v any) any {
-	return v.(num) * a.M.(num)
+	x, ok := Float64(v)
+	m, mok := Float64(valueOf(a.M))
+	if !ok || !mok {
+		return v
+	}
+	return numberLike(x*m, v, a.M)
 }
 
+// AddScalar adds C to numbers and appends it to strings.
+// Other values are returned unchanged.
 type AddScalar struct {
 	C any
 }
@@ -35,54 +44,120 @@ func (a AddScalar) Map(v any) any {
 	if s, ok := v.(str); ok {
 		return s + a.C.(str)
 	}
-	if n, ok := v.(num); ok {
-		return num(n) + a.C.(num)
+	x, ok := Float64(v)
+	c, cok := Float64(valueOf(a.C))
+	if !ok || !cok {
+		return v
 	}
-	return v
+	return numberLike(x+c, v, a.C)
 }
 
+// MathMapper maps numbers with Fn.
+// Other values are returned unchanged.
 type MathMapper struct {
 	Fn func(float64) float64
 }
 
 func (a MathMapper) Map(v any) any {
-	return num(a.Fn(float64(v.(num))))
+	x, ok := Float64(v)
+	if !ok {
+		return v
+	}
+	return numberLike(a.Fn(x), v)
 }
 
+// Math2Mapper maps arrays of two numbers with Fn2.
+// Other values are returned unchanged.
 type Math2Mapper struct {
 	Fn2 func(float64, float64) float64
 }
 
 func (a Math2Mapper) Map(v any) any {
-	es := v.(array)
-	return num(a.Fn2(float64(es[0].(num)), float64(es[1].(num))))
+	es, ok := Array(v)
+	if !ok || len(es) != 2 {
+		return v
+	}
+	x, xok := Float64(es[0])
+	y, yok := Float64(es[1])
+	if !xok || !yok {
+		return v
+	}
+	return numberLike(a.Fn2(x, y), es[0], es[1])
 }
 
+// ObjectMapper maps the entries of an object with the Mapper of their key.
+//
+// It modifies jsong objects in place. Go values are converted with
+// ValueOf and are not modified. See CloneMapper. Values which ValueOf
+// cannot convert map to nil.
 type ObjectMapper map[string]Mapper
 
 func (a ObjectMapper) Map(v any) any {
-	val := ValueOf(v).(object)
+	val := valueOf(v)
+	if val == nil {
+		return nil
+	}
 	for k, m := range a {
-		e := val.At(k)
-		val = Merge(val, m.Map(e), k, "").(object)
+		e, _ := val.Get(k)
+		val = Merge(val, m.Map(e), JoinKey("", k), "").(valueInterface)
 	}
-	return v
+	return val
 }
 
+// ArrayMapper maps the elements of an array with the Mapper of their index.
+//
+// It modifies jsong arrays in place. Go values are converted with
+// ValueOf and are not modified. See CloneMapper. Values which ValueOf
+// cannot convert map to nil. Persistent arrays are never modified and
+// map to a new persistent array.
 type ArrayMapper []Mapper
 
 func (a ArrayMapper) Map(v any) any {
-	val := ValueOf(v).(array)
+	switch val := valueOf(v).(type) {
+	case array:
+		a.mapElements(val)
+		return val
+	case *persistentArray:
+		es := materialize(val).(array)
+		a.mapElements(es)
+		return freeze(es)
+	default:
+		return nil
+	}
+}
+
+func (a ArrayMapper) mapElements(val array) {
 	for i, m := range a {
 		val[i] = m.Map(val[i])
 	}
-	return val
 }
 
+// CloneMapper maps a deep copy of its input with the Mapper
+// leaving the input unchanged. Values which ValueOf cannot
+// convert map to nil.
+type CloneMapper struct {
+	Mapper
+}
+
+func (a CloneMapper) Map(v any) any {
+	c := Clone(v)
+	if c == nil && v != nil {
+		return nil
+	}
+	return a.Mapper.Map(c)
+}
+
+// ArrayRemapper returns a new array of the elements given by the
+// values, Mappers or paths of its elements. The elements are
+// shared with the input. Values which ValueOf cannot convert
+// map to nil.
 type ArrayRemapper []any
 
 func (a ArrayRemapper) Map(v any) any {
-	src := ValueOf(v)
+	src := valueOf(v)
+	if src == nil {
+		return nil
+	}
 	dst := make(array, len(a))
 	for i, e := range a {
 		switch e := e.(type) {
@@ -99,19 +174,27 @@ func (a ArrayRemapper) Map(v any) any {
 	return dst
 }
 
+// ObjectRemapper returns a new object of the entries given by the
+// values, Mappers or paths of its entries. The entries are
+// shared with the input. Values which ValueOf cannot convert
+// map to nil.
 type ObjectRemapper map[string]any
 
 func (a ObjectRemapper) Map(v any) any {
-	src := ValueOf(v)
+	src := valueOf(v)
+	if src == nil {
+		return nil
+	}
 	dst := make(object, len(a))
 	for k, e := range a {
 		switch e := e.(type) {
 		case valueInterface:
 			dst[k] = e
 		case Mapper:
-			dst[k] = e.Map(src.(object).At(k))
+			v, _ := src.(valueInterface).Get(k)
+			dst[k] = e.Map(v)
 		case string:
-			dst[k] = Extract(src, k)
+			dst[k] = Extract(src, e)
 		default:
 			panic(fmt.Errorf("ObjectRemapper: unexpected entry at %q: %T", k, e))
 		}
@@ -0,0 +1,79 @@
+package jsong
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNumberMappers(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		mapper Mapper
+		input  any
+		want   any
+	}{
+		{name: "add num", mapper: AddScalar{num(2)}, input: num(4), want: num(6)},
+		{name: "add decimal", mapper: AddScalar{num(2)}, input: decimal("4"), want: decimal("6")},
+		{name: "add decimal scalar", mapper: AddScalar{decimal("0.5")}, input: num(1), want: decimal("1.5")},
+		{name: "add Go scalar", mapper: AddScalar{2}, input: decimal("4"), want: decimal("6")},
+		{name: "add string", mapper: AddScalar{str("b")}, input: str("a"), want: str("ab")},
+		{name: "add to bool", mapper: AddScalar{num(1)}, input: boolean(true), want: boolean(true)},
+		{name: "mul num", mapper: MulScalar{num(3)}, input: num(2), want: num(6)},
+		{name: "mul decimal", mapper: MulScalar{num(3)}, input: decimal("2.5"), want: decimal("7.5")},
+		{name: "mul string", mapper: MulScalar{num(3)}, input: str("a"), want: str("a")},
+		{name: "math num", mapper: MathMapper{math.Sqrt}, input: num(9), want: num(3)},
+		{name: "math decimal", mapper: MathMapper{math.Sqrt}, input: decimal("9e0"), want: decimal("3")},
+		{name: "math decimal overflow", mapper: MathMapper{math.Exp}, input: decimal("1e9"), want: num(math.Inf(1))},
+		{name: "math2 num", mapper: Math2Mapper{math.Pow}, input: array{num(2), num(10)}, want: num(1024)},
+		{name: "math2 decimal", mapper: Math2Mapper{math.Pow}, input: array{decimal("2"), num(10)}, want: decimal("1024")},
+		{name: "math2 frozen", mapper: Math2Mapper{math.Max}, input: Freeze(array{num(1), decimal("2")}), want: decimal("2")},
+		{name: "math2 short", mapper: Math2Mapper{math.Pow}, input: array{num(2)}, want: array{num(2)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.mapper.Map(tc.input)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Map(): got diff:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNumberMappersUseNumber(t *testing.T) {
+	v, err := DecoderOptions{UseNumber: true}.NewDecoder(strings.NewReader(`{"a": 4, "b": 0.1}`)).Decode()
+	if err != nil {
+		t.Fatalf("Decode(): got err = %v, want err = false", err)
+	}
+
+	got := ObjectMapper{"a": AddScalar{num(2)}, "b": MathMapper{math.Floor}}.Map(v)
+
+	want := object{"a": decimal("6"), "b": decimal("0")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Map(): got diff:\n%s", diff)
+	}
+}
+
+func TestObjectMapperKeys(t *testing.T) {
+	v := object{"/a": num(1), "a.b": num(2), "0": num(3), "-1": num(4), "a": object{"b": num(5)}}
+
+	got := ObjectMapper{"/a": AddScalar{num(10)}, "a.b": AddScalar{num(10)}, "0": AddScalar{num(10)}, "-1": AddScalar{num(10)}}.Map(v)
+
+	want := object{"/a": num(11), "a.b": num(12), "0": num(13), "-1": num(14), "a": object{"b": num(5)}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Map(): got diff:\n%s", diff)
+	}
+}
+
+func TestObjectRemapperPaths(t *testing.T) {
+	v := object{"/a": num(1), "a": object{"b": num(2)}}
+
+	got := ObjectRemapper{"/a": "a.b", "x": "/a/b", "y": `"/a"`}.Map(v)
+
+	want := object{"/a": num(2), "x": num(2), "y": num(1)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Map(): got diff:\n%s", diff)
+	}
+}
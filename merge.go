@@ -2,56 +2,57 @@ package jsong
 
 // Merge the field of the JSON object data with the MergeOptions.
 // It returns the resulting JSON data or any merge errors.
+//
+// The value at srcPath is set at dstPath like Set. The dst is
+// returned unchanged if dstPath conflicts with it or srcPath
+// is missing from src. A src which ValueOf cannot convert is missing
+// and a dst which ValueOf cannot convert returns nil.
+//
+// Merge modifies the objects and arrays of jsong values in place and
+// the result shares the value at srcPath with src. Go values are
+// converted with ValueOf and are not modified. See MergeCopy.
 func Merge(dst, src any, dstPath, srcPath string) any {
-	if _, ok := dst.(valueInterface); !ok {
-		dst = ValueOf(dst)
-	}
-	src = Extract(src, srcPath)
-	return mergeRec(dst.(valueInterface), src.(valueInterface), dstPath)
+	return mergeString(dst, src, dstPath, srcPath, false)
+}
+
+// MergeCopy is like Merge but leaves dst unchanged. Only the objects
+// and arrays along dstPath are copied and the result shares the other
+// values with dst and src. Use Clone for an independent copy.
+func MergeCopy(dst, src any, dstPath, srcPath string) any {
+	return mergeString(dst, src, dstPath, srcPath, true)
 }
 
-func mergeRec(dst, src valueInterface, dstPath string) valueInterface {
-	if dstPath == "" {
-		return src
+func mergeString(dst, src any, dstPath, srcPath string, clone bool) any {
+	dv := valueOf(dst)
+	if dv == nil {
+		return nil
 	}
-	head, tail, leaf := CutKey(dstPath)
-	if head == "" {
-		return dst
+	p, ok := parsePathIn(dv, dstPath)
+	if !ok {
+		// Invalid paths leave dst unchanged like conflicts.
+		return dv
+	}
+	return merge(dv, Extract(src, srcPath), p, clone)
+}
+
+// MergePath is like Merge but takes parsed Paths.
+func MergePath(dst, src any, dstPath, srcPath Path) any {
+	return merge(valueOf(dst), ExtractPath(src, srcPath), dstPath, false)
+}
+
+func merge(dst valueInterface, src any, dstPath Path, clone bool) any {
+	if dst == nil {
+		return nil
 	}
-	if !leaf && tail == "" {
+	sv, ok := src.(valueInterface)
+	if !ok {
+		// Missing sources leave dst unchanged like conflicts.
 		return dst
 	}
-	switch dst := dst.(type) {
-	case null:
-		switch head := head.(type) {
-		case int64:
-			return mergeRec(make(array, head+1), src, dstPath)
-		case string:
-			return mergeRec(make(object), src, dstPath)
-		default:
-			return dst
-		}
-	case array:
-		i := head.(int64)
-		if int64(len(dst)) <= i {
-			return dst
-		}
-		if leaf {
-			dst[i] = src
-			return dst
-		}
-		return mergeRec(dst.At(int(i)), src, tail)
-	case object:
-		if leaf {
-			dst[head.(string)] = src
-			return dst
-		}
-		v, ok := dst[head.(string)]
-		if !ok {
-			v = make(object)
-		}
-		return mergeRec(v.(valueInterface), src, tail)
-	default:
+	res, err := setAt(dst, dstPath, sv, clone)
+	if err != nil {
+		// Paths which conflict with dst leave it unchanged.
 		return dst
 	}
+	return res
 }
@@ -54,3 +54,49 @@ func TestMerge(t *testing.T) {
 		})
 	}
 }
+
+func TestMergePath(t *testing.T) {
+	for _, tc := range []mergeTestCase{{
+		name:     "existing object returns root",
+		dst:      map[string]any{"a": map[string]any{"b": 1}, "c": 2},
+		src:      3,
+		dstField: "a.b",
+		want:     object{"a": object{"b": num(3)}, "c": num(2)},
+	}, {
+		name:     "new intermediate objects",
+		dst:      map[string]any{},
+		src:      1,
+		dstField: "a.b.c",
+		want:     object{"a": object{"b": object{"c": num(1)}}},
+	}, {
+		name:     "out of bounds index",
+		dst:      map[string]any{"a": []any{0}},
+		src:      1,
+		dstField: "a.2",
+		want:     object{"a": array{num(0), null{}, num(1)}},
+	}, {
+		name:     "src path",
+		dst:      map[string]any{},
+		src:      map[string]any{"x": map[string]any{"y": "z"}},
+		dstField: "a",
+		srcField: "x.y",
+		want:     object{"a": str("z")},
+	}, {
+		name:     "conflict",
+		dst:      map[string]any{"a": 1},
+		src:      2,
+		dstField: "a.b",
+		want:     object{"a": num(1)},
+	}, {
+		name:     "missing src path",
+		dst:      object{"a": num(1)},
+		src:      object{},
+		dstField: "b",
+		srcField: "missing",
+		want:     object{"a": num(1)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
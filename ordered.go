@@ -0,0 +1,98 @@
+package jsong
+
+import "slices"
+
+// orderedObject is an object which keeps its keys in insertion order.
+//
+// Putting an existing key replaces its value but keeps its position.
+type orderedObject struct {
+	keys []string
+	m    object
+}
+
+func newOrderedObject(n int) *orderedObject {
+	return &orderedObject{keys: make([]string, 0, n), m: make(object, n)}
+}
+
+func (a *orderedObject) At(k string) valueInterface {
+	return a.m.At(k)
+}
+
+func (a *orderedObject) Get(k any) (valueInterface, bool) {
+	return a.m.Get(k)
+}
+
+func (a *orderedObject) Put(k any, v valueInterface) {
+	if k, ok := k.(string); ok {
+		a.set(k, v)
+	}
+}
+
+func (a *orderedObject) set(k string, v any) {
+	if _, ok := a.m[k]; !ok {
+		a.keys = append(a.keys, k)
+	}
+	a.m[k] = v
+}
+
+func (a *orderedObject) Delete(k any) {
+	if k, ok := k.(string); ok {
+		if _, ok := a.m[k]; ok {
+			delete(a.m, k)
+			i := slices.Index(a.keys, k)
+			a.keys = slices.Delete(a.keys, i, i+1)
+		}
+	}
+}
+
+func (a *orderedObject) Each(fn func(k, v any) bool) {
+	for _, k := range a.keys {
+		if !fn(k, a.m[k]) {
+			break
+		}
+	}
+}
+
+func (a *orderedObject) Len() int { return len(a.keys) }
+
+// compare compares the objects ignoring key order.
+func (a *orderedObject) compare(other valueInterface) int {
+	return a.m.compare(other)
+}
+
+// MarshalJSON writes the object with its keys in order.
+func (a *orderedObject) MarshalJSON() ([]byte, error) {
+	return new(Encoder).appendValue(nil, a, 0)
+}
+
+// isObject reports whether v is an object of either kind.
+func isObject(v valueInterface) bool {
+	switch v.(type) {
+	case object, *orderedObject:
+		return true
+	default:
+		return false
+	}
+}
+
+// newObjectLike returns a new empty object which keeps
+// its keys in order if v does and is persistent if v is.
+func newObjectLike(v valueInterface) valueInterface {
+	switch v.(type) {
+	case *orderedObject:
+		return newOrderedObject(0)
+	case persistentValue:
+		return emptyPersistentObject
+	default:
+		return object{}
+	}
+}
+
+// newArrayLike returns a new empty array
+// which is persistent if v is.
+func newArrayLike(v valueInterface) valueInterface {
+	if _, ok := v.(persistentValue); ok {
+		return emptyPersistentArray
+	}
+	return array{}
+}
@@ -0,0 +1,153 @@
+package jsong
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const orderedInput = `{"z":1,"a":{"y":[true,{"c":null,"b":2}],"x":"s"},"m":3}`
+
+func decodeOrdered(t testing.TB, input string) any {
+	t.Helper()
+	v, err := DecoderOptions{OrderedObjects: true}.NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("Decode(): got err = %v, want err = false", err)
+	}
+	return v
+}
+
+func encodeString(t *testing.T, opts EncoderOptions, v any) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := opts.NewEncoder(&sb).Encode(v); err != nil {
+		t.Fatalf("Encode(): got err = %v, want err = false", err)
+	}
+	return sb.String()
+}
+
+func TestDecodeOrderedObjects(t *testing.T) {
+	v := decodeOrdered(t, orderedInput)
+
+	if diff := cmp.Diff(orderedInput+"\n", encodeString(t, EncoderOptions{}, v)); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+
+	want := `{"a":{"x":"s","y":[true,{"b":2,"c":null}]},"m":3,"z":1}` + "\n"
+	if diff := cmp.Diff(want, encodeString(t, EncoderOptions{SortKeys: true}, v)); diff != "" {
+		t.Errorf("Encode(SortKeys): got diff:\n%s", diff)
+	}
+}
+
+func TestDecodeOrderedObjectsDuplicateKey(t *testing.T) {
+	v := decodeOrdered(t, `{"a":1,"b":2,"a":3}`)
+
+	if diff := cmp.Diff(`{"a":3,"b":2}`+"\n", encodeString(t, EncoderOptions{}, v)); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+}
+
+func TestDecodePathsOrderedObjects(t *testing.T) {
+	dec := DecoderOptions{OrderedObjects: true}.NewDecoder(strings.NewReader(orderedInput))
+
+	v, err := dec.DecodePaths("m", "a.y.1.*", "z")
+	if err != nil {
+		t.Fatalf("DecodePaths(): got err = %v, want err = false", err)
+	}
+
+	want := `{"z":1,"a":{"y":[null,{"c":null,"b":2}]},"m":3}` + "\n"
+	if diff := cmp.Diff(want, encodeString(t, EncoderOptions{}, v)); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+}
+
+func TestVisitOrderedObjects(t *testing.T) {
+	v := decodeOrdered(t, orderedInput)
+
+	var got []string
+	Visit(v, func(k string, _ any) error {
+		got = append(got, k)
+		return nil
+	})
+
+	want := []string{"", "z", "a", "a.y", "a.y.0", "a.y.1", "a.y.1.c", "a.y.1.b", "a.x", "m"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Visit(): got diff:\n%s", diff)
+	}
+}
+
+func TestDeleteOrderedObjects(t *testing.T) {
+	v := decodeOrdered(t, orderedInput)
+
+	v = Delete(v, "a.y.1.c")
+	v = Delete(v, "z")
+
+	want := `{"a":{"y":[true,{"b":2}],"x":"s"},"m":3}` + "\n"
+	if diff := cmp.Diff(want, encodeString(t, EncoderOptions{}, v)); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+}
+
+func TestMergeOrderedObjects(t *testing.T) {
+	v := decodeOrdered(t, orderedInput)
+
+	v = Merge(v, str("new"), "b", "")
+	v = Merge(v, num(0), "z", "")
+
+	want := `{"z":0,"a":{"y":[true,{"c":null,"b":2}],"x":"s"},"m":3,"b":"new"}` + "\n"
+	if diff := cmp.Diff(want, encodeString(t, EncoderOptions{}, v)); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+}
+
+func TestCompareOrderedObjects(t *testing.T) {
+	a := decodeOrdered(t, `{"b":1,"a":2}`)
+	b := object{"a": num(2), "b": num(1)}
+
+	if got := Compare(a, b); got != 0 {
+		t.Errorf("Compare(): got %d, want 0", got)
+	}
+	if got := Compare(b, a); got != 0 {
+		t.Errorf("Compare(): got %d, want 0", got)
+	}
+	if got := Compare(a, object{"a": num(3), "b": num(1)}); got != -1 {
+		t.Errorf("Compare(): got %d, want -1", got)
+	}
+}
+
+type orderedStruct struct {
+	Z int
+	A string
+	M map[string]int
+	orderedEmbedded
+}
+
+type orderedEmbedded struct {
+	Y bool
+	B float64
+}
+
+func TestValueOfOrderedObjects(t *testing.T) {
+	v := ValueOptions{OrderedObjects: true}.ValueOf(orderedStruct{
+		Z: 1,
+		A: "a",
+		M: map[string]int{"c": 3, "a": 1, "b": 2},
+	})
+
+	want := `{"Z":1,"A":"a","M":{"a":1,"b":2,"c":3},"Y":false,"B":0}` + "\n"
+	if diff := cmp.Diff(want, encodeString(t, EncoderOptions{}, v)); diff != "" {
+		t.Errorf("Encode(): got diff:\n%s", diff)
+	}
+}
+
+func TestOrderedObjectMarshalJSON(t *testing.T) {
+	got, err := json.Marshal(decodeOrdered(t, orderedInput))
+	if err != nil {
+		t.Fatalf("json.Marshal(): got err: %v", err)
+	}
+	if diff := cmp.Diff(orderedInput, string(got)); diff != "" {
+		t.Errorf("json.Marshal(): got diff:\n%s", diff)
+	}
+}
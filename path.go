@@ -0,0 +1,81 @@
+package jsong
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Path is a parsed path of object keys and array indices.
+//
+// The keys are strings, the indices are int64s and slices of arrays
+// are Slices. Negative indices count from the end. Functions taking
+// a Path are like the functions of the same name taking a path string
+// but don't parse the path on each call. The empty Path is the root.
+type Path []any
+
+// ParsePath parses the path s into its segments with CutKey.
+// It returns an error if s has an empty unquoted segment.
+// The empty path s is the root. Paths beginning with a slash
+// are parsed as JSON Pointers like ParsePointer.
+func ParsePath(s string) (Path, error) {
+	p, ok := parsePath(s)
+	if !ok {
+		if pointerHint(s) {
+			return nil, fmt.Errorf("ParsePath: invalid JSON pointer %q", s)
+		}
+		return nil, fmt.Errorf("ParsePath: empty key in path %q", s)
+	}
+	return p, nil
+}
+
+func parsePath(s string) (Path, bool) {
+	if s == "" {
+		return nil, true
+	}
+	if pointerHint(s) {
+		tokens, ok := pointerTokens(s)
+		if !ok {
+			return nil, false
+		}
+		return pointerPath(tokens), true
+	}
+	var p Path
+	for {
+		if s == "" || s[0] == byte(dot) {
+			return nil, false // Empty segment.
+		}
+		head, tail, leaf := CutKey(s)
+		p = append(p, head)
+		if leaf {
+			return p, true
+		}
+		s = tail
+	}
+}
+
+// String returns the path string of p using JoinKey.
+func (p Path) String() string { return JoinKey("", p...) }
+
+// Append returns a new path with the keys ks appended to p.
+//
+// Keys should be either string, int64 or Slice
+// or else Append panics.
+func (p Path) Append(ks ...any) Path {
+	for _, k := range ks {
+		switch k.(type) {
+		case string, int64, Slice:
+		default:
+			panic(fmt.Errorf("Path.Append: unexpected type in key at %T", k))
+		}
+	}
+	return append(slices.Clip(p), ks...)
+}
+
+// Parent returns the path of the parent of p.
+// The root has no parent and returns nil.
+func (p Path) Parent() Path {
+	if len(p) == 0 {
+		return nil
+	}
+	return slices.Clip(p[:len(p)-1])
+}
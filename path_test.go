@@ -0,0 +1,105 @@
+package jsong
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePath(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		want    Path
+		wantErr bool
+	}{
+		{input: "", want: nil},
+		{input: "a", want: Path{"a"}},
+		{input: "a.0.b", want: Path{"a", int64(0), "b"}},
+		{input: `"a.b"."0".""`, want: Path{"a.b", "0", ""}},
+		{input: "a.-", want: Path{"a", "-"}},
+		{input: `a.-1.1:3."-1"`, want: Path{"a", int64(-1), Slice{1, 3}, "-1"}},
+		{input: ".a", wantErr: true},
+		{input: "a..b", wantErr: true},
+		{input: "a.", wantErr: true},
+		{input: `"a".`, wantErr: true},
+	} {
+		got, err := ParsePath(tc.input)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("ParsePath(%q): got err = %v, want err = %v", tc.input, err, tc.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParsePath(%q): got diff:\n%s", tc.input, diff)
+		}
+		if err == nil {
+			if got := Must(ParsePath(got.String())); !cmp.Equal(got, tc.want) {
+				t.Errorf("ParsePath(%q).String(): got %v after parsing again, want %v", tc.input, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestPathAppendParent(t *testing.T) {
+	p := Path{"a", int64(1)}
+
+	child := p.Append("b.c")
+	sibling := child.Parent().Append("d")
+
+	if got, want := child.String(), `a.1."b.c"`; got != want {
+		t.Errorf("Append(): got %s, want %s", got, want)
+	}
+	if got, want := sibling.String(), "a.1.d"; got != want {
+		t.Errorf("Parent().Append(): got %s, want %s", got, want)
+	}
+	if got, want := child.String(), `a.1."b.c"`; got != want {
+		t.Errorf("Parent().Append(): modified the child path to %s, want %s", got, want)
+	}
+	if got := (Path{}).Parent(); got != nil {
+		t.Errorf("Parent(): got %v for the root, want nil", got)
+	}
+}
+
+func TestPathAppendPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Append(1): got panic = false, want panic = true")
+		}
+	}()
+	Path{}.Append(1)
+}
+
+func TestPathFunctions(t *testing.T) {
+	newInput := func() any {
+		return object{
+			"a": array{object{"k": num(2)}, object{"k": num(1)}},
+			"b": object{"c.d": str("e")},
+		}
+	}
+
+	for _, path := range []string{"", "a", "a.0.k", `b."c.d"`, "x.y"} {
+		p := Must(ParsePath(path))
+		if diff := cmp.Diff(Extract(newInput(), path), ExtractPath(newInput(), p)); diff != "" {
+			t.Errorf("ExtractPath(%q): got diff:\n%s", path, diff)
+		}
+		if diff := cmp.Diff(Delete(newInput(), path), DeletePath(newInput(), p)); diff != "" {
+			t.Errorf("DeletePath(%q): got diff:\n%s", path, diff)
+		}
+		want, wantErr := Set(newInput(), path, 1)
+		got, err := SetPath(newInput(), p, 1)
+		if (err != nil) != (wantErr != nil) {
+			t.Errorf("SetPath(%q): got err = %v, want err = %v", path, err, wantErr)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("SetPath(%q): got diff:\n%s", path, diff)
+		}
+		if diff := cmp.Diff(Merge(newInput(), newInput(), "z", path), MergePath(newInput(), newInput(), Path{"z"}, p)); diff != "" {
+			t.Errorf("MergePath(%q): got diff:\n%s", path, diff)
+		}
+	}
+
+	got := SortByKeyPath(Extract(newInput(), "a"), Path{"k"})
+
+	if diff := cmp.Diff(SortByKey(Extract(newInput(), "a"), "k"), got); diff != "" {
+		t.Errorf("SortByKeyPath(): got diff:\n%s", diff)
+	}
+}
@@ -0,0 +1,246 @@
+package jsong
+
+// persistentValue is implemented by the immutable objects and arrays
+// returned by Freeze. Instead of modifying the value, with and without
+// return a new value which shares the unchanged parts with the old one.
+type persistentValue interface {
+	valueInterface
+	// with returns the value with the key k set to v.
+	// It reports false if k is not a key of the value's kind.
+	with(k any, v valueInterface) (valueInterface, bool)
+	// without returns the value without the key k.
+	without(k any) valueInterface
+}
+
+// Freeze returns a persistent copy of the value v.
+//
+// The objects and arrays of persistent values are immutable. Set,
+// Delete and Merge return a new value which shares the unchanged
+// objects and arrays with the old one, so old versions remain valid
+// and updates only copy the path to the change. Objects are hash
+// array mapped tries and arrays are tries of 32 element chunks.
+// Persistent objects do not keep their keys in order.
+//
+// Persistent values work with the other functions of this package.
+// Use Thaw for a mutable copy. Go values are converted with ValueOf.
+// Values which ValueOf cannot convert return nil.
+func Freeze(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case valueInterface:
+		return freeze(v)
+	default:
+		rv := valueOf(v)
+		if rv == nil {
+			return nil
+		}
+		return freeze(rv)
+	}
+}
+
+func freeze(v valueInterface) valueInterface {
+	switch v := v.(type) {
+	case persistentValue:
+		return v
+	case array:
+		es := make([]any, len(v))
+		for i, e := range v {
+			if e != nil {
+				e = freeze(e.(valueInterface))
+			}
+			es[i] = e
+		}
+		return &persistentArray{vectorOf(es)}
+	case object, *orderedObject:
+		var entries []hamtEntry
+		v.Each(func(k, e any) bool {
+			if e != nil {
+				e = freeze(e.(valueInterface))
+			}
+			entries = append(entries, hamtEntry{key: k.(string), value: e})
+			return true
+		})
+		return &persistentObject{root: hamtOf(entries), n: len(entries)}
+	default:
+		// Scalars are immutable.
+		return v
+	}
+}
+
+// Thaw returns a mutable copy of the value v with the persistent
+// objects and arrays of v replaced by objects and arrays.
+// Like Clone, the result shares no objects or arrays with v.
+// Values which ValueOf cannot convert return nil.
+func Thaw(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case valueInterface:
+		return cloneValue(v, true)
+	default:
+		return valueOf(v)
+	}
+}
+
+// materialize returns a persistent value v as an object or array
+// which shares its elements with v. Other values are returned as is.
+func materialize(v valueInterface) valueInterface {
+	switch v := v.(type) {
+	case *persistentArray:
+		res := make(array, 0, v.n)
+		v.each(func(_ int, e any) bool {
+			res = append(res, e)
+			return true
+		})
+		return res
+	case *persistentObject:
+		res := make(object, v.n)
+		v.root.each(func(k string, e any) bool {
+			res[k] = e
+			return true
+		})
+		return res
+	default:
+		return v
+	}
+}
+
+// persistentObject is an immutable object. See Freeze.
+type persistentObject struct {
+	root *hamtNode
+	n    int
+}
+
+var (
+	emptyPersistentObject = &persistentObject{root: new(hamtNode)}
+	emptyPersistentArray  = new(persistentArray)
+)
+
+func (a *persistentObject) Get(k any) (valueInterface, bool) {
+	if k, ok := k.(string); ok {
+		v, ok := a.root.get(hamtHash(k), 0, k)
+		if !ok {
+			return nil, false
+		}
+		return asValue(v), true
+	}
+	return nil, false
+}
+
+// Put panics because persistent objects are immutable.
+func (a *persistentObject) Put(k any, v valueInterface) {
+	panic("jsong: Put on persistent object")
+}
+
+// Delete panics because persistent objects are immutable.
+func (a *persistentObject) Delete(k any) {
+	panic("jsong: Delete on persistent object")
+}
+
+func (a *persistentObject) Each(fn func(k, v any) bool) {
+	a.root.each(func(k string, v any) bool { return fn(k, v) })
+}
+
+func (a *persistentObject) Len() int { return a.n }
+
+func (a *persistentObject) with(k any, v valueInterface) (valueInterface, bool) {
+	key, ok := k.(string)
+	if !ok {
+		return a, false
+	}
+	root, added := a.root.with(hamtHash(key), 0, key, v)
+	res := &persistentObject{root: root, n: a.n}
+	if added {
+		res.n++
+	}
+	return res, true
+}
+
+func (a *persistentObject) without(k any) valueInterface {
+	key, ok := k.(string)
+	if !ok {
+		return a
+	}
+	root, removed := a.root.without(hamtHash(key), 0, key)
+	if !removed {
+		return a
+	}
+	return &persistentObject{root: root, n: a.n - 1}
+}
+
+// compare compares the objects like objects.
+func (a *persistentObject) compare(other valueInterface) int {
+	if b, ok := other.(*persistentObject); ok && a.root == b.root {
+		return 0
+	}
+	return materialize(a).compare(materialize(other))
+}
+
+// MarshalJSON writes the object.
+func (a *persistentObject) MarshalJSON() ([]byte, error) {
+	return new(Encoder).appendValue(nil, a, 0)
+}
+
+// persistentArray is an immutable array. See Freeze.
+type persistentArray struct {
+	vector
+}
+
+func (a *persistentArray) Get(k any) (valueInterface, bool) {
+	if i, ok := k.(int64); ok && i >= 0 && i < int64(a.n) {
+		return asValue(a.get(int(i))), true
+	}
+	return nil, false
+}
+
+// Put panics because persistent arrays are immutable.
+func (a *persistentArray) Put(k any, v valueInterface) {
+	panic("jsong: Put on persistent array")
+}
+
+// Delete panics because persistent arrays are immutable.
+func (a *persistentArray) Delete(k any) {
+	panic("jsong: Delete on persistent array")
+}
+
+func (a *persistentArray) Each(fn func(k, v any) bool) {
+	a.each(func(i int, v any) bool { return fn(int64(i), v) })
+}
+
+func (a *persistentArray) Len() int { return a.n }
+
+// with sets the element k or appends it if k is the length of a.
+func (a *persistentArray) with(k any, v valueInterface) (valueInterface, bool) {
+	i, ok := k.(int64)
+	switch {
+	case !ok || i < 0 || i > int64(a.n):
+		return a, false
+	case i == int64(a.n):
+		return &persistentArray{a.push(v)}, true
+	default:
+		return &persistentArray{a.vector.with(int(i), v)}, true
+	}
+}
+
+// without clears the element k like Delete on arrays.
+func (a *persistentArray) without(k any) valueInterface {
+	i, ok := k.(int64)
+	if !ok || i < 0 || i >= int64(a.n) {
+		return a
+	}
+	return &persistentArray{a.vector.with(int(i), nil)}
+}
+
+// compare compares the arrays like arrays.
+func (a *persistentArray) compare(other valueInterface) int {
+	if b, ok := other.(*persistentArray); ok && a.root == b.root && a.n == b.n {
+		return 0
+	}
+	return materialize(a).compare(materialize(other))
+}
+
+// MarshalJSON writes the array.
+func (a *persistentArray) MarshalJSON() ([]byte, error) {
+	return new(Encoder).appendValue(nil, a, 0)
+}
@@ -0,0 +1,340 @@
+package jsong
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newPersistentInput() any {
+	return object{
+		"a": array{num(3), str("x"), object{"b": str("c")}},
+		"d": object{"e": array{}, "f": object{}},
+		"g": boolean(true),
+	}
+}
+
+func TestFreezeThaw(t *testing.T) {
+	v := Freeze(newPersistentInput())
+
+	if _, ok := v.(*persistentObject); !ok {
+		t.Fatalf("Freeze(): got %T, want persistent object", v)
+	}
+	if _, ok := Extract(v, "a").(*persistentArray); !ok {
+		t.Errorf("Freeze(): got %T at a, want persistent array", Extract(v, "a"))
+	}
+	got := Thaw(v)
+	if diff := cmp.Diff(newPersistentInput(), got); diff != "" {
+		t.Errorf("Thaw(Freeze()): got diff:\n%s", diff)
+	}
+	got.(object)["a"].(array)[0] = null{}
+	if diff := cmp.Diff(newPersistentInput(), Thaw(v)); diff != "" {
+		t.Errorf("Thaw(): modifying the result modified the input:\n%s", diff)
+	}
+	if got := Freeze(v); got != v {
+		t.Errorf("Freeze(): got a copy of a persistent value")
+	}
+	if got := Freeze(map[string]int{"a": 1}); KindOf(got) != KindObject || Extract(got, "a") != num(1) {
+		t.Errorf("Freeze(): got %v for a Go value, want object", got)
+	}
+}
+
+func TestPersistentSet(t *testing.T) {
+	v := Freeze(newPersistentInput())
+
+	for _, tc := range []struct {
+		path string
+		x    any
+	}{
+		{path: "a.2.b", x: 1},
+		{path: "a.4", x: "y"},
+		{path: "a.-", x: []int{1}},
+		{path: "d.f.x.0.y", x: true},
+		{path: "h", x: map[string]any{"i": 1}},
+	} {
+		want := Must(Set(newPersistentInput(), tc.path, tc.x))
+
+		got := Must(Set(v, tc.path, tc.x))
+
+		if diff := cmp.Diff(want, Thaw(got)); diff != "" {
+			t.Errorf("Set(%q): got diff:\n%s", tc.path, diff)
+		}
+		if diff := cmp.Diff(newPersistentInput(), Thaw(v)); diff != "" {
+			t.Errorf("Set(%q): modified the persistent input:\n%s", tc.path, diff)
+		}
+		Visit(got, func(k string, e any) error {
+			if KindOf(e) >= KindArray {
+				if _, ok := e.(persistentValue); !ok {
+					t.Errorf("Set(%q): got mutable %T at %q", tc.path, e, k)
+				}
+			}
+			return nil
+		})
+	}
+
+	if _, err := Set(v, "a.b", 1); err == nil {
+		t.Errorf("Set(): got err = false for a key through an array, want err = true")
+	}
+}
+
+func TestPersistentSharing(t *testing.T) {
+	v := Freeze(newPersistentInput())
+
+	got := Must(Set(v, "a.2.b", 1))
+
+	if Extract(got, "d") != Extract(v, "d") {
+		t.Errorf("Set(): got a copy of the unchanged object d, want shared")
+	}
+	if Extract(got, "a") == Extract(v, "a") {
+		t.Errorf("Set(): got the same array a along the path, want copy")
+	}
+}
+
+func TestPersistentDelete(t *testing.T) {
+	v := Freeze(newPersistentInput())
+
+	for _, path := range []string{"a.2.b", "a.0", "d.e", "g", "x.y", "a.9"} {
+		want := Delete(newPersistentInput(), path)
+
+		got := Delete(v, path)
+
+		if diff := cmp.Diff(want, Thaw(got)); diff != "" {
+			t.Errorf("Delete(%q): got diff:\n%s", path, diff)
+		}
+		if diff := cmp.Diff(want, Thaw(DeleteCopy(v, path))); diff != "" {
+			t.Errorf("DeleteCopy(%q): got diff:\n%s", path, diff)
+		}
+		if diff := cmp.Diff(newPersistentInput(), Thaw(v)); diff != "" {
+			t.Errorf("Delete(%q): modified the persistent input:\n%s", path, diff)
+		}
+	}
+}
+
+func TestPersistentMerge(t *testing.T) {
+	dst := Freeze(newPersistentInput())
+	src := object{"x": object{"y": num(1)}}
+
+	got := Merge(dst, src, "d.f", "x")
+
+	want := Merge(newPersistentInput(), src, "d.f", "x")
+	if diff := cmp.Diff(want, Thaw(got)); diff != "" {
+		t.Errorf("Merge(): got diff:\n%s", diff)
+	}
+	if diff := cmp.Diff(newPersistentInput(), Thaw(dst)); diff != "" {
+		t.Errorf("Merge(): modified the persistent input:\n%s", diff)
+	}
+	src["x"].(object)["y"] = num(2)
+	if got := Extract(got, "d.f.y"); got != num(1) {
+		t.Errorf("Merge(): got %v after modifying src, want 1", got)
+	}
+}
+
+func TestPersistentSort(t *testing.T) {
+	newInput := func() any {
+		return array{object{"k": num(2)}, object{"k": num(3)}, object{"k": num(1)}}
+	}
+	v := Freeze(newInput())
+
+	for name, tc := range map[string]struct {
+		got, want any
+	}{
+		"Sort":          {Sort(v), Sort(newInput())},
+		"SortCopy":      {SortCopy(v), SortCopy(newInput())},
+		"SortByKey":     {SortByKey(v, "k"), SortByKey(newInput(), "k")},
+		"SortByKeyPath": {SortByKeyPath(v, Path{"k"}), SortByKeyPath(newInput(), Path{"k"})},
+		"SortByKeyCopy": {SortByKeyCopy(v, "k"), SortByKeyCopy(newInput(), "k")},
+	} {
+		if _, ok := tc.got.(*persistentArray); !ok {
+			t.Errorf("%s(): got %T, want a persistent array", name, tc.got)
+		}
+		if diff := cmp.Diff(tc.want, Thaw(tc.got)); diff != "" {
+			t.Errorf("%s(): got diff:\n%s", name, diff)
+		}
+	}
+	if diff := cmp.Diff(newInput(), Thaw(v)); diff != "" {
+		t.Errorf("Sort(): modified the persistent input:\n%s", diff)
+	}
+}
+
+func TestPersistentArrayMapper(t *testing.T) {
+	newInput := func() any { return array{num(1), object{"a": num(2)}, str("x")} }
+	v := Freeze(newInput())
+	m := ArrayMapper{AddScalar{num(1)}, ObjectMapper{"a": MulScalar{num(3)}}}
+
+	got := m.Map(v)
+
+	if _, ok := got.(*persistentArray); !ok {
+		t.Errorf("ArrayMapper(): got %T, want a persistent array", got)
+	}
+	if _, ok := Extract(got, "1").(*persistentObject); !ok {
+		t.Errorf("ArrayMapper(): got %T for a mapped element, want a persistent object", Extract(got, "1"))
+	}
+	want := array{num(2), object{"a": num(6)}, str("x")}
+	if diff := cmp.Diff(want, Thaw(got)); diff != "" {
+		t.Errorf("ArrayMapper(): got diff:\n%s", diff)
+	}
+	if diff := cmp.Diff(newInput(), Thaw(v)); diff != "" {
+		t.Errorf("ArrayMapper(): modified the persistent input:\n%s", diff)
+	}
+}
+
+func TestPersistentCompare(t *testing.T) {
+	values := []any{
+		null{},
+		boolean(true),
+		num(1),
+		str("a"),
+		array{},
+		array{num(1)},
+		array{num(1), num(2)},
+		array{num(2)},
+		object{},
+		object{"a": num(1)},
+		object{"a": num(2)},
+		object{"b": num(1)},
+		newPersistentInput(),
+	}
+	for _, a := range values {
+		for _, b := range values {
+			want := Compare(a, b)
+			if got := Compare(Freeze(a), Freeze(b)); got != want {
+				t.Errorf("Compare(Freeze(%v), Freeze(%v)): got %d, want %d", a, b, got, want)
+			}
+			if got := Compare(Freeze(a), b); got != want {
+				t.Errorf("Compare(Freeze(%v), %v): got %d, want %d", a, b, got, want)
+			}
+			if got := Compare(a, Freeze(b)); got != want {
+				t.Errorf("Compare(%v, Freeze(%v)): got %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestPersistentVisit(t *testing.T) {
+	visitPaths := func(v any) []string {
+		var paths []string
+		Visit(v, func(k string, _ any) error {
+			paths = append(paths, k)
+			return nil
+		})
+		slices.Sort(paths)
+		return paths
+	}
+
+	got := visitPaths(Freeze(newPersistentInput()))
+
+	if diff := cmp.Diff(visitPaths(newPersistentInput()), got); diff != "" {
+		t.Errorf("Visit(): got diff:\n%s", diff)
+	}
+}
+
+func TestPersistentView(t *testing.T) {
+	v := View(Freeze(newPersistentInput()))
+
+	if got, want := v.Keys(), []string{"a", "d", "g"}; !slices.Equal(got, want) {
+		t.Errorf("Keys(): got %v, want %v", got, want)
+	}
+	if got := v.Field("a").Len(); got != 3 {
+		t.Errorf("Len(): got %d, want 3", got)
+	}
+	if got := v.Field("a").Index(2).Field("b").Interface(); got != str("c") {
+		t.Errorf("Index(2).Field(b): got %v, want c", got)
+	}
+	if v.Field("a").Index(3).IsValid() {
+		t.Errorf("Index(3): got valid, want invalid")
+	}
+	var dst struct {
+		A []any
+		D map[string]any
+	}
+	if err := Into(v.Interface(), &dst); err != nil {
+		t.Fatalf("Into(): got err = %v, want err = false", err)
+	}
+	if diff := cmp.Diff([]any{3.0, "x", map[string]any{"b": "c"}}, dst.A); diff != "" {
+		t.Errorf("Into(): got diff:\n%s", diff)
+	}
+}
+
+func TestPersistentEncode(t *testing.T) {
+	v := Freeze(newPersistentInput())
+
+	got := encodeString(t, EncoderOptions{SortKeys: true}, v)
+
+	if want := encodeString(t, EncoderOptions{SortKeys: true}, newPersistentInput()); got != want {
+		t.Errorf("Encode(): got %s, want %s", got, want)
+	}
+}
+
+func TestPersistentLarge(t *testing.T) {
+	const n = 5000
+	obj, arr := Freeze(object{}), Freeze(array{})
+	versions := make([]any, 0, n)
+	for i := range n {
+		obj = Must(Set(obj, "k"+strconv.Itoa(i), i))
+		arr = Must(Set(arr, "-", i))
+		versions = append(versions, arr)
+	}
+
+	if got := View(obj).Len(); got != n {
+		t.Fatalf("Set(): got %d keys, want %d", got, n)
+	}
+	if got := View(arr).Len(); got != n {
+		t.Fatalf("Set(): got %d elements, want %d", got, n)
+	}
+	for i := range n {
+		if got := Extract(obj, "k"+strconv.Itoa(i)); got != num(i) {
+			t.Fatalf("Extract(k%d): got %v, want %d", i, got, i)
+		}
+		if got := Extract(arr, strconv.Itoa(i)); got != num(i) {
+			t.Fatalf("Extract(%d): got %v, want %d", i, got, i)
+		}
+		if got := View(versions[i]).Len(); got != i+1 {
+			t.Fatalf("Set(): got %d elements in version %d, want %d", got, i, i+1)
+		}
+	}
+	if got := Compare(Freeze(Thaw(arr)), arr); got != 0 {
+		t.Errorf("Compare(Freeze(Thaw())): got %d, want 0", got)
+	}
+
+	for i := 0; i < n; i += 2 {
+		obj = Delete(obj, "k"+strconv.Itoa(i))
+	}
+	if got := View(obj).Len(); got != n/2 {
+		t.Errorf("Delete(): got %d keys, want %d", got, n/2)
+	}
+	for i := range n {
+		_, ok := obj.(valueInterface).Get("k" + strconv.Itoa(i))
+		if want := i%2 == 1; ok != want {
+			t.Fatalf("Delete(): got key k%d = %v, want %v", i, ok, want)
+		}
+	}
+}
+
+func TestHamtCollisions(t *testing.T) {
+	// Use the same hash for all keys.
+	const n = 10
+	node := new(hamtNode)
+	for i := range n {
+		node, _ = node.with(0, 0, fmt.Sprint(i), num(i))
+	}
+	for i := range n {
+		if got, ok := node.get(0, 0, fmt.Sprint(i)); !ok || got != num(i) {
+			t.Errorf("get(%d): got %v, %v, want %d", i, got, ok, i)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		node, _ = node.without(0, 0, fmt.Sprint(i))
+	}
+	var keys []string
+	node.each(func(k string, _ any) bool {
+		keys = append(keys, k)
+		return true
+	})
+	slices.Sort(keys)
+	if want := []string{"1", "3", "5", "7", "9"}; !slices.Equal(keys, want) {
+		t.Errorf("without(): got keys %v, want %v", keys, want)
+	}
+}
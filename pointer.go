@@ -0,0 +1,139 @@
+package jsong
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Paths beginning with a slash are JSON Pointers (RFC 6901) like "/a/b~1c/0"
+// where "~1" escapes "/" and "~0" escapes "~" in the reference tokens.
+//
+// A pointer doesn't say whether a token like "0" is an object key or an
+// array index. Extract, Delete, Set and Merge resolve the tokens against
+// the value: tokens are indices for arrays and missing values and keys
+// otherwise. Other functions taking paths, like ParsePath, treat every
+// token of the form of an array index as an index.
+
+func pointerHint(s string) bool { return strings.HasPrefix(s, "/") }
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// ParsePointer parses the JSON Pointer s into a Path.
+// The empty pointer s is the root.
+func ParsePointer(s string) (Path, error) {
+	tokens, ok := pointerTokens(s)
+	if !ok {
+		return nil, fmt.Errorf("ParsePointer: invalid JSON pointer %q", s)
+	}
+	return pointerPath(tokens), nil
+}
+
+// Pointer returns p as a JSON Pointer.
+func (p Path) Pointer() string {
+	var sb strings.Builder
+	for _, k := range p {
+		sb.WriteByte('/')
+		switch k := k.(type) {
+		case int64:
+			sb.WriteString(strconv.FormatInt(k, 10))
+		case string:
+			pointerEscaper.WriteString(&sb, k)
+		default:
+			panic(fmt.Errorf("Path.Pointer: unexpected type in key at %T", k))
+		}
+	}
+	return sb.String()
+}
+
+// pointerTokens returns the unescaped reference tokens of the pointer s.
+// It reports false if s doesn't begin with a slash or has invalid escapes.
+func pointerTokens(s string) ([]string, bool) {
+	if s == "" {
+		return nil, true
+	}
+	if !pointerHint(s) {
+		return nil, false
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, tok := range tokens {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] != '~' {
+				continue
+			}
+			if j+1 == len(tok) || tok[j+1] != '0' && tok[j+1] != '1' {
+				return nil, false
+			}
+			j++
+		}
+		tokens[i] = pointerUnescaper.Replace(tok)
+	}
+	return tokens, true
+}
+
+// pointerIndex returns the token as an array index if it is one.
+// Unlike paths, pointer indices have no leading zeros.
+func pointerIndex(tok string) (int64, bool) {
+	if tok == "" || tok[0] == '0' && tok != "0" {
+		return 0, false
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.ParseInt(tok, 10, 64)
+	return i, err == nil
+}
+
+// pointerPath returns the path of the tokens
+// treating every index token as an index.
+func pointerPath(tokens []string) Path {
+	if len(tokens) == 0 {
+		return nil
+	}
+	p := make(Path, len(tokens))
+	for i, tok := range tokens {
+		if j, ok := pointerIndex(tok); ok {
+			p[i] = j
+		} else {
+			p[i] = tok
+		}
+	}
+	return p
+}
+
+// resolvePointer returns the path of the tokens in the value v
+// using index tokens as indices only for arrays and missing values.
+func resolvePointer(v valueInterface, tokens []string) Path {
+	p := make(Path, len(tokens))
+	for i, tok := range tokens {
+		var k any = tok
+		if kind := KindOf(v); kind == KindArray || kind == KindNull {
+			if j, ok := pointerIndex(tok); ok {
+				k = j
+			}
+		}
+		p[i] = k
+		if v != nil {
+			v, _ = v.Get(k)
+		}
+	}
+	return p
+}
+
+// parsePathIn parses the path s of the value v
+// resolving JSON Pointers against v.
+func parsePathIn(v valueInterface, s string) (Path, bool) {
+	if !pointerHint(s) {
+		return parsePath(s)
+	}
+	tokens, ok := pointerTokens(s)
+	if !ok {
+		return nil, false
+	}
+	return resolvePointer(v, tokens), true
+}
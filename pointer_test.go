@@ -0,0 +1,123 @@
+package jsong
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePointer(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		want    Path
+		wantErr bool
+	}{
+		{input: "", want: nil},
+		{input: "/", want: Path{""}},
+		{input: "/a/b~1c/0", want: Path{"a", "b/c", int64(0)}},
+		{input: "/~01/~10", want: Path{"~1", "/0"}},
+		{input: "/a.b/00/-", want: Path{"a.b", "00", "-"}},
+		{input: "//", want: Path{"", ""}},
+		{input: "a/b", wantErr: true},
+		{input: "/a~", wantErr: true},
+		{input: "/a~2", wantErr: true},
+	} {
+		got, err := ParsePointer(tc.input)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("ParsePointer(%q): got err = %v, want err = %v", tc.input, err, tc.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParsePointer(%q): got diff:\n%s", tc.input, diff)
+		}
+		if err == nil {
+			if got := got.Pointer(); got != tc.input {
+				t.Errorf("ParsePointer(%q).Pointer(): got %q, want the input", tc.input, got)
+			}
+		}
+	}
+}
+
+func TestPointerPath(t *testing.T) {
+	p := Must(ParsePath("/a/b~1c/0"))
+
+	if got, want := p.String(), "a.b/c.0"; got != want {
+		t.Errorf("String(): got %s, want %s", got, want)
+	}
+	if got, want := Must(ParsePath(`a."/b".0`)).Pointer(), "/a/~1b/0"; got != want {
+		t.Errorf("Pointer(): got %s, want %s", got, want)
+	}
+	if _, err := ParsePath("/a~"); err == nil {
+		t.Errorf("ParsePath(/a~): got err = false, want err = true")
+	}
+}
+
+func TestPointerFunctions(t *testing.T) {
+	newInput := func() any {
+		return object{
+			"a":   array{num(1), object{"0": str("zero"), "1": num(2)}},
+			"b/c": object{"~": boolean(true)},
+			"/d":  num(3),
+		}
+	}
+
+	for _, tc := range []struct {
+		pointer string
+		path    string
+	}{
+		{pointer: "", path: ""},
+		{pointer: "/a/1/0", path: `a.1."0"`},
+		{pointer: "/a/0", path: "a.0"},
+		{pointer: "/b~1c/~0", path: `"b/c".~`},
+		{pointer: "/~1d", path: `"/d"`},
+		{pointer: "/x/0", path: "x.0"},
+	} {
+		if diff := cmp.Diff(Extract(newInput(), tc.path), Extract(newInput(), tc.pointer)); diff != "" {
+			t.Errorf("Extract(%q): got diff:\n%s", tc.pointer, diff)
+		}
+		if diff := cmp.Diff(Delete(newInput(), tc.path), Delete(newInput(), tc.pointer)); diff != "" {
+			t.Errorf("Delete(%q): got diff:\n%s", tc.pointer, diff)
+		}
+		if diff := cmp.Diff(Must(Set(newInput(), tc.path, 1)), Must(Set(newInput(), tc.pointer, 1))); diff != "" {
+			t.Errorf("Set(%q): got diff:\n%s", tc.pointer, diff)
+		}
+	}
+
+	if got := Must(Set(newInput(), "/a/-", 4)); Extract(got, "a.2") != num(4) {
+		t.Errorf("Set(/a/-): got %v, want appended 4", got)
+	}
+	if _, err := Set(newInput(), "/a~", 1); err == nil {
+		t.Errorf("Set(/a~): got err = false, want err = true")
+	}
+}
+
+func TestJoinKeyQuotePointer(t *testing.T) {
+	got := JoinKey("", "/a", "/b")
+
+	if want := `"/a"."/b"`; got != want {
+		t.Errorf("JoinKey(): got %s, want %s", got, want)
+	}
+	if got := Extract(object{"/a": object{"/b": num(1)}}, got); got != num(1) {
+		t.Errorf("Extract(): got %v, want 1", got)
+	}
+}
+
+func FuzzPointer(f *testing.F) {
+	for _, seed := range []struct{ a, b string }{
+		{"a", "b"},
+		{"", "0"},
+		{"~", "/"},
+		{"~01", "~1/"},
+	} {
+		f.Add(seed.a, seed.b)
+	}
+	f.Fuzz(func(t *testing.T, a, b string) {
+		ptr := Path{a, b}.Pointer()
+
+		got, ok := pointerTokens(ptr)
+
+		if want := []string{a, b}; !ok || !cmp.Equal(want, got) {
+			t.Errorf("pointerTokens(%q): got %q, %v, want %q", ptr, got, ok, want)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package jsong
+
+// DecodePaths decodes the next JSON value keeping only the values
+// at the given paths and skipping all other data in the input.
+//
+// Paths may be globs as accepted by CompileKeyMatcher.
+// The result retains the structure of the input so that Extract
+// returns the same values for the given paths as on the fully decoded value.
+// Objects only contain keys leading to a matched value while skipped
+// array elements are replaced with null to preserve indices.
+// DecodePaths returns null when no value matches.
+func (d *Decoder) DecodePaths(paths ...string) (any, error) {
+	ms := make([]*KeyMatcher, 0, len(paths))
+	for _, p := range paths {
+		m, err := CompileKeyMatcher(p)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	if len(d.tokens) == 0 {
+		d.path = d.path[:0]
+		d.depth = 0
+		if err := d.skipWhitespace(); err != nil {
+			return nil, err
+		}
+	}
+	p := &projection{d: d, ms: ms}
+	return d.elementValue(func() (any, error) {
+		v, ok, err := p.decode()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return null{}, nil
+		}
+		return v, nil
+	}, false)
+}
+
+// projection decodes the values matching any of the key matchers.
+type projection struct {
+	d    *Decoder
+	ms   []*KeyMatcher
+	path []any // Path relative to the projected value.
+}
+
+func (p *projection) match() (full, prefix bool) {
+	k := JoinKey("", p.path...)
+	for _, m := range p.ms {
+		if m.MatchKey(k) {
+			return true, true
+		}
+		if m.matchPrefix(p.path) {
+			prefix = true
+		}
+	}
+	return false, prefix
+}
+
+// decode decodes the next value or skips it when no path
+// can match it or its descendants.
+func (p *projection) decode() (v any, ok bool, err error) {
+	full, prefix := p.match()
+	if full {
+		v, err := p.d.decodeValue()
+		return v, err == nil, err
+	}
+	if !prefix {
+		return nil, false, p.d.skipValue()
+	}
+	if err := p.d.skipWhitespace(); err != nil {
+		return nil, false, p.d.ioError(err)
+	}
+	b, err := p.d.peekByte()
+	if err != nil {
+		return nil, false, p.d.ioError(err)
+	}
+	switch b {
+	case '[':
+		return p.decodeArray()
+	case '{':
+		return p.decodeObject()
+	default:
+		return nil, false, p.d.skipValue()
+	}
+}
+
+func (p *projection) decodeArray() (any, bool, error) {
+	d := p.d
+	if err := d.enter(); err != nil {
+		return nil, false, err
+	}
+	defer d.exit()
+	res := array{}
+	last := -1   // Index of the last matched element.
+	d.discard(1) // '['
+	for i := 0; ; i++ {
+		if err := d.skipWhitespace(); err != nil {
+			return nil, false, d.ioError(err)
+		}
+		b, err := d.peekByte()
+		if err != nil {
+			return nil, false, d.ioError(err)
+		}
+		if b == ']' {
+			d.discard(1)
+			break
+		}
+		if i > 0 {
+			if b != ',' {
+				return nil, false, d.syntaxError(0, "invalid character %q after array element", b)
+			}
+			d.discard(1)
+		}
+		d.path = append(d.path, int64(i))
+		p.path = append(p.path, int64(i))
+		e, ok, err := p.decode()
+		if err != nil {
+			return nil, false, err
+		}
+		d.path = d.path[:len(d.path)-1]
+		p.path = p.path[:len(p.path)-1]
+		if ok {
+			last = i
+		} else {
+			e = null{}
+		}
+		res = append(res, e)
+	}
+	if last < 0 {
+		return nil, false, nil
+	}
+	// Trim skipped trailing elements.
+	return res[:last+1], true, nil
+}
+
+func (p *projection) decodeObject() (any, bool, error) {
+	d := p.d
+	if err := d.enter(); err != nil {
+		return nil, false, err
+	}
+	defer d.exit()
+	res := object{}
+	var keys []string
+	d.discard(1) // '{'
+	for i := 0; ; i++ {
+		if err := d.skipWhitespace(); err != nil {
+			return nil, false, d.ioError(err)
+		}
+		b, err := d.peekByte()
+		if err != nil {
+			return nil, false, d.ioError(err)
+		}
+		if b == '}' {
+			d.discard(1)
+			break
+		}
+		if i > 0 {
+			if b != ',' {
+				return nil, false, d.syntaxError(0, "invalid character %q after object key:value pair", b)
+			}
+			d.discard(1)
+			if err := d.skipWhitespace(); err != nil {
+				return nil, false, d.ioError(err)
+			}
+			if b, err = d.peekByte(); err != nil {
+				return nil, false, d.ioError(err)
+			}
+		}
+		if b != '"' {
+			return nil, false, d.syntaxError(0, "invalid character %q looking for beginning of object key string", b)
+		}
+		k, err := d.decodeString()
+		if err != nil {
+			return nil, false, err
+		}
+		if err := d.skipWhitespace(); err != nil {
+			return nil, false, d.ioError(err)
+		}
+		if b, err = d.peekByte(); err != nil {
+			return nil, false, d.ioError(err)
+		}
+		if b != ':' {
+			return nil, false, d.syntaxError(0, "invalid character %q after object key", b)
+		}
+		d.discard(1)
+		d.path = append(d.path, string(k.(str)))
+		p.path = append(p.path, string(k.(str)))
+		v, ok, err := p.decode()
+		if err != nil {
+			return nil, false, err
+		}
+		d.path = d.path[:len(d.path)-1]
+		p.path = p.path[:len(p.path)-1]
+		if ok {
+			if _, dup := res[string(k.(str))]; !dup && d.opts.OrderedObjects {
+				keys = append(keys, string(k.(str)))
+			}
+			res[string(k.(str))] = v
+		}
+	}
+	if len(res) == 0 {
+		return nil, false, nil
+	}
+	if d.opts.OrderedObjects {
+		return &orderedObject{keys: keys, m: res}, true, nil
+	}
+	return res, true, nil
+}
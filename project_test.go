@@ -0,0 +1,123 @@
+package jsong
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type decodePathsTestCase struct {
+	name    string
+	input   string
+	paths   []string
+	want    any
+	wantErr bool
+}
+
+func (tc decodePathsTestCase) runTest(t *testing.T) {
+	t.Helper()
+	got, err := NewDecoder(strings.NewReader(tc.input)).DecodePaths(tc.paths...)
+	if gotErr := err != nil; gotErr != tc.wantErr {
+		t.Fatalf("DecodePaths(%q): got err = %v, want err = %v", tc.name, err, tc.wantErr)
+	}
+	if diff := cmp.Diff(tc.want, got); diff != "" {
+		t.Errorf("DecodePaths(%q): got diff:\n%s", tc.name, diff)
+	}
+	if tc.wantErr {
+		return
+	}
+	// Check that Extract sees the same values on the projected value.
+	v := Must(NewDecoder(strings.NewReader(tc.input)).Decode())
+	for _, path := range tc.paths {
+		if strings.Contains(path, string(star)) {
+			continue
+		}
+		if diff := cmp.Diff(Extract(v, path), Extract(got, path)); diff != "" {
+			t.Errorf("DecodePaths(%q): got Extract(%q) diff:\n%s", tc.name, path, diff)
+		}
+	}
+}
+
+func TestDecodePaths(t *testing.T) {
+	for _, tc := range []decodePathsTestCase{{
+		name:  "root",
+		input: `{"a": 1}`,
+		paths: []string{""},
+		want:  object{"a": num(1)},
+	}, {
+		name:  "nested path",
+		input: `{"a": {"b": [0, 1, 2, 3, {"c": "x", "d": "y"}, 5]}, "e": [1, 2, 3]}`,
+		paths: []string{"a.b.4.c"},
+		want:  object{"a": object{"b": array{null{}, null{}, null{}, null{}, object{"c": str("x")}}}},
+	}, {
+		name:  "multiple paths",
+		input: `{"a": 1, "b": {"c": 2, "d": 3}, "e": 4}`,
+		paths: []string{"a", "b.d"},
+		want:  object{"a": num(1), "b": object{"d": num(3)}},
+	}, {
+		name:  "matched null in array",
+		input: `[1, null, 2]`,
+		paths: []string{"1"},
+		want:  array{null{}, null{}},
+	}, {
+		name:  "missing path",
+		input: `{"a": {"b": 1}}`,
+		paths: []string{"a.c"},
+		want:  null{},
+	}, {
+		name:  "scalar on path",
+		input: `{"a": 1}`,
+		paths: []string{"a.b"},
+		want:  null{},
+	}, {
+		name:  "star",
+		input: `{"a": [{"id": 1, "x": 0}, {"id": 2, "x": 0}], "b": {"id": 3}}`,
+		paths: []string{"a.*.id"},
+		want:  object{"a": array{object{"id": num(1)}, object{"id": num(2)}}},
+	}, {
+		name:  "star prefix",
+		input: `{"k1": {"v": 1}, "k2": {"v": 2}, "x": {"v": 3}}`,
+		paths: []string{"k*.v"},
+		want:  object{"k1": object{"v": num(1)}, "k2": object{"v": num(2)}},
+	}, {
+		name:  "double star",
+		input: `{"a": {"b": {"id": 1}, "id": 2}, "c": [{"id": 3}]}`,
+		paths: []string{"**.id"},
+		want:  object{"a": object{"b": object{"id": num(1)}, "id": num(2)}, "c": array{object{"id": num(3)}}},
+	}, {
+		name:  "quoted key",
+		input: `{"a.b": {"c": 1, "d": 2}, "a": {"b": {"c": 3}}}`,
+		paths: []string{JoinKey("", "a.b", "c")},
+		want:  object{"a.b": object{"c": num(1)}},
+	}, {
+		name:  "quoted star",
+		input: `{"*": 1, "a": 2}`,
+		paths: []string{`"*"`},
+		want:  object{"*": num(1)},
+	}, {
+		name:  "negative index",
+		input: `{"a": [{"id": 1, "x": 0}, {"id": 2, "x": 0}]}`,
+		paths: []string{"a.-1.id"},
+		want:  object{"a": array{object{"id": num(1)}, object{"id": num(2)}}},
+	}, {
+		name:  "slice",
+		input: `{"a": [{"id": 1, "x": 0}, {"id": 2, "x": 0}]}`,
+		paths: []string{"a.1:.id"},
+		want:  object{"a": array{object{"id": num(1)}, object{"id": num(2)}}},
+	}, {
+		name:    "syntax error in skipped data",
+		input:   `{"a": [1, 2,], "b": 1}`,
+		paths:   []string{"b"},
+		wantErr: true,
+	}, {
+		name:    "invalid number in skipped data",
+		input:   `{"a": [1-2], "b": 1}`,
+		paths:   []string{"b"},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
@@ -46,8 +46,13 @@ func (a *PartitionReducer) Reset() {
 	a.once = sync.Once{}
 }
 
+// Add adds x to the partition of its value at Key.
+// Values without the key are ignored.
 func (a *PartitionReducer) Add(x any) {
-	h := Extract(x, a.Key).(valueInterface)
+	h, ok := Extract(x, a.Key).(valueInterface)
+	if !ok {
+		return
+	}
 	a.once.Do(func() { a.partitions = make(map[valueInterface]Reducer) })
 	r, ok := a.partitions[h]
 	if !ok {
@@ -67,10 +72,16 @@ func (a *PartitionReducer) Value() any {
 
 type ObjectReducer map[string]Reducer
 
+// Add adds the values of the keys of the object x to their Reducers.
+// Values which ValueOf cannot convert are ignored.
 func (a ObjectReducer) Add(x any) {
-	val := ValueOf(x).(object)
+	val := valueOf(x)
+	if val == nil {
+		return
+	}
 	for k, r := range a {
-		r.Add(val[k])
+		v, _ := val.Get(k)
+		r.Add(v)
 	}
 }
 
@@ -84,8 +95,13 @@ func (a ObjectReducer) Value() any {
 
 type ArrayReducer []Reducer
 
+// Add adds the elements of the array x to their Reducers.
+// Other values are ignored.
 func (a ArrayReducer) Add(x any) {
-	val := ValueOf(x).(array)
+	val, ok := valueOf(x).(array)
+	if !ok {
+		return
+	}
 	for i, r := range a {
 		r.Add(val[i])
 	}
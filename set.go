@@ -0,0 +1,202 @@
+package jsong
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPathConflict is returned by Set when the path
+// goes through a value which cannot contain it.
+var ErrPathConflict = errors.New("path conflict")
+
+// maxArrayGrowth is the largest number of elements
+// Set adds to an array to reach an index.
+const maxArrayGrowth = 1 << 16
+
+// Set sets the path in the value v to the value of x and returns the result.
+//
+// Missing and null values along the path are created as objects for keys
+// and arrays for indices. Arrays are grown with nulls up to the index and
+// the index "-" appends to an array. Indices more than 65536 past the end
+// of an array are rejected. Negative indices count from the end of an
+// array and must be in its range. Set returns an error wrapping
+// ErrPathConflict when the path goes through a scalar, an index through
+// an object or a key through an array. Paths may also be JSON Pointers.
+//
+// The empty path returns the value of x. Set modifies the objects and
+// arrays of jsong values in place. Go values are converted with ValueOf
+// and are not modified. See SetCopy. Persistent values are never modified
+// and the values set in them are converted with Freeze. See Freeze.
+func Set(v any, path string, x any) (any, error) {
+	return set(v, path, x, false)
+}
+
+// SetCopy is like Set but leaves v unchanged. Only the objects and
+// arrays along the path are copied and the result shares the other
+// values with v. Use Clone for an independent copy.
+func SetCopy(v any, path string, x any) (any, error) {
+	return set(v, path, x, true)
+}
+
+func set(v any, path string, x any, clone bool) (any, error) {
+	dst, err := setValueOf(v)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := parsePathIn(dst, path)
+	if !ok {
+		return nil, fmt.Errorf("Set: invalid path %q", path)
+	}
+	return setValue(dst, p, x, clone)
+}
+
+// SetPath is like Set but takes a parsed Path.
+func SetPath(v any, p Path, x any) (any, error) {
+	return setValue(v, p, x, false)
+}
+
+func setValue(v any, p Path, x any, clone bool) (any, error) {
+	dst, err := setValueOf(v)
+	if err != nil {
+		return nil, err
+	}
+	xv, err := setValueOf(x)
+	if err != nil {
+		return nil, err
+	}
+	res, err := setAt(dst, p, xv, clone)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// setValueOf returns the jsong value of v with nil pointers as null.
+func setValueOf(v any) (valueInterface, error) {
+	res, err := ValueOfErr(v)
+	if err != nil {
+		return nil, err
+	}
+	rv, ok := res.(valueInterface)
+	if !ok {
+		return null{}, nil // Nil pointers.
+	}
+	return rv, nil
+}
+
+// setAt sets the path p in dst to x and returns the result.
+// With clone, the objects and arrays along the path are copied
+// instead of modified.
+func setAt(dst valueInterface, p Path, x valueInterface, clone bool) (valueInterface, error) {
+	return setRec(nil, dst, p, 0, x, clone)
+}
+
+// setRec sets the remaining path p[at:] in dst which is contained by parent.
+func setRec(parent, dst valueInterface, p Path, at int, x valueInterface, clone bool) (valueInterface, error) {
+	if at == len(p) {
+		return x, nil
+	}
+	head := p[at]
+	if dst == nil || dst == (null{}) {
+		// Create the missing container.
+		if head == "-" {
+			dst = newArrayLike(parent)
+		} else if _, ok := head.(int64); ok {
+			dst = newArrayLike(parent)
+		} else {
+			dst = newObjectLike(parent)
+		}
+	} else if clone && (isObject(dst) || KindOf(dst) == KindArray) {
+		dst = shallowClone(dst)
+	}
+	conflict := func() error {
+		return fmt.Errorf("Set: %w: %v at %q", ErrPathConflict, KindOf(dst), p[:at].String())
+	}
+	outOfRange := func() error {
+		return fmt.Errorf("Set: %w: index %v out of range at %q", ErrPathConflict, head, p[:at].String())
+	}
+	switch d := dst.(type) {
+	case array:
+		var i int64
+		switch head := head.(type) {
+		case int64:
+			if i = head; i < 0 {
+				if i += int64(len(d)); i < 0 {
+					return nil, outOfRange()
+				}
+			}
+		case string:
+			if head != "-" {
+				return nil, conflict()
+			}
+			i = int64(len(d))
+		default:
+			return nil, conflict()
+		}
+		if i-int64(len(d)) >= maxArrayGrowth {
+			return nil, outOfRange()
+		}
+		for int64(len(d)) <= i {
+			d = append(d, null{})
+		}
+		next, _ := d[i].(valueInterface)
+		e, err := setRec(d, next, p, at+1, x, clone)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = e
+		return d, nil
+	case object, *orderedObject:
+		k, ok := head.(string)
+		if !ok {
+			return nil, conflict()
+		}
+		next, _ := d.Get(k)
+		e, err := setRec(d, next, p, at+1, x, clone)
+		if err != nil {
+			return nil, err
+		}
+		d.Put(k, e)
+		return d, nil
+	case *persistentArray:
+		i, ok := head.(int64)
+		if head == "-" {
+			i, ok = int64(d.Len()), true
+		}
+		if !ok {
+			return nil, conflict()
+		}
+		if i < 0 {
+			if i += int64(d.Len()); i < 0 {
+				return nil, outOfRange()
+			}
+		}
+		if i-int64(d.Len()) >= maxArrayGrowth {
+			return nil, outOfRange()
+		}
+		for int64(d.Len()) < i {
+			d = &persistentArray{d.push(null{})}
+		}
+		next, _ := d.Get(i)
+		e, err := setRec(d, next, p, at+1, x, clone)
+		if err != nil {
+			return nil, err
+		}
+		res, _ := d.with(i, freeze(e))
+		return res, nil
+	case *persistentObject:
+		k, ok := head.(string)
+		if !ok {
+			return nil, conflict()
+		}
+		next, _ := d.Get(k)
+		e, err := setRec(d, next, p, at+1, x, clone)
+		if err != nil {
+			return nil, err
+		}
+		res, _ := d.with(k, freeze(e))
+		return res, nil
+	default:
+		return nil, conflict()
+	}
+}
@@ -0,0 +1,204 @@
+package jsong
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type setTestCase struct {
+	name    string
+	input   any
+	path    string
+	x       any
+	want    any
+	wantErr bool
+}
+
+func (tc setTestCase) runTest(t *testing.T) {
+	t.Helper()
+	got, err := Set(tc.input, tc.path, tc.x)
+	if gotErr := err != nil; gotErr != tc.wantErr {
+		t.Fatalf("Set(%q): got err = %v, want err = %v", tc.name, err, tc.wantErr)
+	}
+	if diff := cmp.Diff(tc.want, got); diff != "" {
+		t.Errorf("Set(%q): got diff:\n%s", tc.name, diff)
+	}
+}
+
+func TestSet(t *testing.T) {
+	for _, tc := range []setTestCase{{
+		name:  "empty path",
+		input: object{"a": num(1)},
+		x:     "b",
+		want:  str("b"),
+	}, {
+		name: "nil",
+		path: "a.b",
+		x:    1,
+		want: object{"a": object{"b": num(1)}},
+	}, {
+		name:  "existing key",
+		input: object{"a": object{"b": num(1), "c": num(2)}},
+		path:  "a.b",
+		x:     3,
+		want:  object{"a": object{"b": num(3), "c": num(2)}},
+	}, {
+		name:  "replace container",
+		input: object{"a": array{num(1)}},
+		path:  "a",
+		x:     nil,
+		want:  object{"a": null{}},
+	}, {
+		name:  "create array",
+		input: object{},
+		path:  "a.2.b",
+		x:     true,
+		want:  object{"a": array{null{}, null{}, object{"b": boolean(true)}}},
+	}, {
+		name:  "grow array",
+		input: array{num(0)},
+		path:  "3",
+		x:     "x",
+		want:  array{num(0), null{}, null{}, str("x")},
+	}, {
+		name:  "append",
+		input: object{"a": array{num(0)}},
+		path:  "a.-",
+		x:     1,
+		want:  object{"a": array{num(0), num(1)}},
+	}, {
+		name:  "append to missing",
+		input: object{},
+		path:  "a.-.-",
+		x:     1,
+		want:  object{"a": array{array{num(1)}}},
+	}, {
+		name:  "null intermediate",
+		input: object{"a": null{}},
+		path:  "a.b",
+		x:     1,
+		want:  object{"a": object{"b": num(1)}},
+	}, {
+		name:  "nil element",
+		input: array{nil},
+		path:  "0.a",
+		x:     1,
+		want:  array{object{"a": num(1)}},
+	}, {
+		name:  "go value",
+		input: map[string][]int{"a": {1}},
+		path:  "a.1",
+		x:     []string{"b"},
+		want:  object{"a": array{num(1), array{str("b")}}},
+	}, {
+		name:    "scalar conflict",
+		input:   object{"a": str("s")},
+		path:    "a.b",
+		x:       1,
+		wantErr: true,
+	}, {
+		name:    "index in object",
+		input:   object{"a": object{}},
+		path:    "a.0",
+		x:       1,
+		wantErr: true,
+	}, {
+		name:    "key in array",
+		input:   object{"a": array{}},
+		path:    "a.b",
+		x:       1,
+		wantErr: true,
+	}, {
+		name:    "empty key",
+		input:   object{},
+		path:    "a..b",
+		x:       1,
+		wantErr: true,
+	}, {
+		name:    "trailing dot",
+		input:   object{},
+		path:    "a.",
+		x:       1,
+		wantErr: true,
+	}, {
+		name:    "unsupported",
+		input:   object{},
+		path:    "a",
+		x:       func() {},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.runTest(t)
+		})
+	}
+}
+
+func TestSetConflictError(t *testing.T) {
+	_, err := Set(object{"a": array{num(1)}}, "a.0.b", 1)
+
+	if !errors.Is(err, ErrPathConflict) {
+		t.Fatalf("Set(): got err = %v, want ErrPathConflict", err)
+	}
+	if got, want := err.Error(), `Set: path conflict: number at "a.0"`; got != want {
+		t.Errorf("Set(): got err = %q, want %q", got, want)
+	}
+}
+
+func TestSetNegativeIndex(t *testing.T) {
+	got, err := Set(object{"a": array{num(1), num(2)}}, "a.-1", 3)
+	if err != nil {
+		t.Fatalf("Set(): got err = %v, want err = false", err)
+	}
+
+	want := object{"a": array{num(1), num(3)}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Set(): got diff:\n%v", diff)
+	}
+
+	for _, path := range []string{"a.-3", "b.-1"} {
+		if _, err := Set(object{"a": array{num(1), num(2)}}, path, 3); err == nil {
+			t.Errorf("Set(%q): got err = false, want err = true", path)
+		}
+		if _, err := Set(Freeze(object{"a": array{num(1), num(2)}}), path, 3); err == nil {
+			t.Errorf("Set(Freeze(), %q): got err = false, want err = true", path)
+		}
+	}
+	if _, err := Set(object{"a": array{num(1)}}, "a.0:1", 3); !errors.Is(err, ErrPathConflict) {
+		t.Errorf("Set(a.0:1): got err = %v, want ErrPathConflict", err)
+	}
+}
+
+func TestSetIndexOutOfRange(t *testing.T) {
+	for _, path := range []string{"a.9223372036854775807", "a.65537", "b.65536"} {
+		if _, err := Set(object{"a": array{num(1)}}, path, 1); !errors.Is(err, ErrPathConflict) {
+			t.Errorf("Set(%q): got err = %v, want ErrPathConflict", path, err)
+		}
+		if _, err := Set(Freeze(object{"a": array{num(1)}}), path, 1); !errors.Is(err, ErrPathConflict) {
+			t.Errorf("Set(Freeze(), %q): got err = %v, want ErrPathConflict", path, err)
+		}
+	}
+
+	got, err := Set(object{"a": array{num(1)}}, "a.65536", 1)
+	if err != nil {
+		t.Fatalf("Set(): got err = %v, want err = false", err)
+	}
+	if n := View(Extract(got, "a")).Len(); n != 65537 {
+		t.Errorf("Set(): got array of length %d, want 65537", n)
+	}
+}
+
+func TestSetOrdered(t *testing.T) {
+	v := decodeOrdered(t, `{"b":1,"a":{"y":2,"x":3}}`)
+
+	got, err := Set(v, "a.z.w", 4)
+	if err != nil {
+		t.Fatalf("Set(): got err = %v, want err = false", err)
+	}
+
+	if got, want := encodeString(t, EncoderOptions{}, got), `{"b":1,"a":{"y":2,"x":3,"z":{"w":4}}}`+"\n"; got != want {
+		t.Errorf("Set(): got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package jsong
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Slice is a path segment like "1:3" for the elements of an array from
+// the index Start up to but not including End. Negative bounds count
+// from the end of the array like negative indices such as "-1" do.
+// An omitted Start is 0 and an omitted End is math.MaxInt64.
+type Slice struct {
+	Start, End int64
+}
+
+// String returns the slice as a path segment.
+func (s Slice) String() string {
+	var sb strings.Builder
+	if s.Start != 0 {
+		sb.WriteString(strconv.FormatInt(s.Start, 10))
+	}
+	sb.WriteByte(':')
+	if s.End != math.MaxInt64 {
+		sb.WriteString(strconv.FormatInt(s.End, 10))
+	}
+	return sb.String()
+}
+
+// bounds returns the indices from start up to end
+// selected by s in an array of length n.
+func (s Slice) bounds(n int) (start, end int64) {
+	length := int64(n)
+	clamp := func(i int64) int64 {
+		if i < 0 {
+			i += length
+		}
+		return min(max(i, 0), length)
+	}
+	start, end = clamp(s.Start), clamp(s.End)
+	return start, max(start, end)
+}
+
+// parseSlice parses the unquoted segment k as a slice
+// of optional indices separated by a colon.
+func parseSlice(k string) (Slice, bool) {
+	start, end, found := strings.Cut(k, ":")
+	if !found {
+		return Slice{}, false
+	}
+	s := Slice{End: math.MaxInt64}
+	var ok bool
+	if start != "" {
+		if s.Start, ok = index(start); !ok {
+			return Slice{}, false
+		}
+	}
+	if end != "" {
+		if s.End, ok = index(end); !ok {
+			return Slice{}, false
+		}
+	}
+	return s, true
+}
+
+// resolveKeys returns the keys of v selected by the path segment k.
+// Negative indices and slices are resolved against arrays
+// and select nothing in other values.
+func resolveKeys(v valueInterface, k any) []any {
+	switch k := k.(type) {
+	case int64:
+		if k >= 0 {
+			break
+		}
+		if KindOf(v) != KindArray {
+			return nil
+		}
+		if i := k + int64(View(v).Len()); i >= 0 {
+			return []any{i}
+		}
+		return nil
+	case Slice:
+		if KindOf(v) != KindArray {
+			return nil
+		}
+		start, end := k.bounds(View(v).Len())
+		keys := make([]any, 0, end-start)
+		for i := start; i < end; i++ {
+			keys = append(keys, i)
+		}
+		return keys
+	}
+	return []any{k}
+}
+
+// relativeIndex returns the glob segment k as a negative index or slice.
+func relativeIndex(k string) (any, bool) {
+	if i, ok := index(k); ok && i < 0 {
+		return i, true
+	}
+	if s, ok := parseSlice(k); ok {
+		return s, true
+	}
+	return nil, false
+}
+
+// selectsIndex reports whether the negative index or slice k
+// selects the index i in an array of length n.
+func selectsIndex(k any, i int64, n int) bool {
+	switch k := k.(type) {
+	case int64:
+		return i == k+int64(n)
+	case Slice:
+		start, end := k.bounds(n)
+		return start <= i && i < end
+	}
+	return false
+}
@@ -4,16 +4,44 @@ import (
 	"sort"
 )
 
+// Sort sorts the array vs with Compare and returns it.
+// Other values are returned unchanged.
+//
+// Sort sorts jsong arrays in place. Go values are converted
+// with ValueOf and are not modified. See SortCopy. Values which
+// ValueOf cannot convert return nil. Persistent arrays are never
+// modified and return a sorted persistent array.
 func Sort(vs any) any {
 	if _, ok := vs.(valueInterface); !ok {
-		vs = ValueOf(vs)
+		vs = valueOf(vs)
 	}
-	a, ok := vs.(array)
-	if !ok {
+	return sortArray(vs, func(a array) { sort.Sort(a) })
+}
+
+// sortArray sorts the array vs with sortFn and returns it.
+// Persistent arrays are sorted into a new persistent array.
+// Other values are returned unchanged.
+func sortArray(vs any, sortFn func(array)) any {
+	switch a := vs.(type) {
+	case array:
+		sortFn(a)
+		return a
+	case *persistentArray:
+		es := materialize(a).(array)
+		sortFn(es)
+		return &persistentArray{vectorOf(es)}
+	default:
 		return vs
 	}
-	sort.Sort(a)
-	return a
+}
+
+// SortCopy is like Sort but returns a sorted copy of the array
+// leaving vs unchanged. The elements are shared with vs.
+func SortCopy(vs any) any {
+	if a, ok := vs.(array); ok {
+		vs = a.Clone()
+	}
+	return Sort(vs)
 }
 
 func (a array) Len() int           { return len(a) }
@@ -21,21 +49,40 @@ func (a array) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a array) Less(i, j int) bool { return compare(a.At(i), a.At(j)) < 0 }
 
 // SortByKey sorts the values by extracting the key using jsong.
+//
+// Like Sort, it sorts jsong arrays in place and returns a new
+// persistent array for persistent arrays. See SortByKeyCopy.
+// Invalid keys leave the values unchanged.
 func SortByKey(vs any, key string) any {
-	if key == "" {
+	p, ok := parsePath(key)
+	if !ok {
+		return valueOf(vs)
+	}
+	return SortByKeyPath(vs, p)
+}
+
+// SortByKeyPath is like SortByKey but takes a parsed Path.
+func SortByKeyPath(vs any, key Path) any {
+	if len(key) == 0 {
 		return Sort(vs)
 	}
 	if _, ok := vs.(valueInterface); !ok {
-		vs = ValueOf(vs)
-	}
-	a, ok := vs.(array)
-	if !ok {
-		return vs
+		vs = valueOf(vs)
 	}
-	sort.Slice(a, func(i, j int) bool {
-		e1 := extractRec(a.At(i), key)
-		e2 := extractRec(a.At(j), key)
-		return compare(e1, e2) < 0
+	return sortArray(vs, func(a array) {
+		sort.Slice(a, func(i, j int) bool {
+			e1 := extractRec(a.At(i), key)
+			e2 := extractRec(a.At(j), key)
+			return compare(e1, e2) < 0
+		})
 	})
-	return a
+}
+
+// SortByKeyCopy is like SortByKey but returns a sorted copy of the
+// array leaving vs unchanged. The elements are shared with vs.
+func SortByKeyCopy(vs any, key string) any {
+	if a, ok := vs.(array); ok {
+		vs = a.Clone()
+	}
+	return SortByKey(vs, key)
 }
@@ -0,0 +1,456 @@
+package jsong
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Delim is an array or object delimiter token: one of [ ] { }.
+type Delim rune
+
+func (d Delim) String() string { return string(d) }
+
+// Key is an object key token.
+type Key string
+
+type tokenState uint8
+
+const (
+	tokenArrayStart  tokenState = iota // After '[': value or ']'.
+	tokenArrayValue                    // After ',': value.
+	tokenArrayComma                    // After value: ',' or ']'.
+	tokenObjectStart                   // After '{': key or '}'.
+	tokenObjectKey                     // After ',': key.
+	tokenObjectColon                   // After key: ':'.
+	tokenObjectValue                   // After ':': value.
+	tokenObjectComma                   // After value: ',' or '}'.
+)
+
+// tokenFrame is an array or object opened by Token.
+type tokenFrame struct {
+	state tokenState
+	seg   any                 // Path segment of the current element.
+	keys  map[string]struct{} // Keys seen when duplicates are disallowed.
+}
+
+func (d *Decoder) frame() *tokenFrame { return &d.tokens[len(d.tokens)-1] }
+
+// Token returns the next JSON token in the input.
+//
+// Token returns a Delim for the start and end of arrays and objects,
+// a Key for object keys and the jsong value for null, boolean,
+// number and string values. Commas and colons are validated and
+// consumed but never returned.
+//
+// Token may be mixed with calls to Decode and Skip which consume the
+// entire next value. Token returns io.EOF at the end of the input
+// when no array or object remains open.
+func (d *Decoder) Token() (any, error) {
+	if len(d.tokens) == 0 {
+		d.path = d.path[:0]
+		d.depth = 0
+	}
+	for {
+		if err := d.skipWhitespace(); err != nil {
+			if len(d.tokens) == 0 {
+				return nil, err
+			}
+			return nil, d.ioError(err)
+		}
+		b, err := d.peekByte()
+		if err != nil {
+			return nil, d.ioError(err)
+		}
+		if len(d.tokens) == 0 {
+			return d.tokenValue(b)
+		}
+		f := d.frame()
+		switch f.state {
+		case tokenArrayStart:
+			if b == ']' {
+				return d.tokenEnd(b)
+			}
+			return d.tokenValue(b)
+		case tokenArrayValue, tokenObjectValue:
+			return d.tokenValue(b)
+		case tokenArrayComma:
+			if b == ']' {
+				return d.tokenEnd(b)
+			}
+			if b != ',' {
+				return nil, d.syntaxError(0, "invalid character %q after array element", b)
+			}
+			d.discard(1)
+			f.state = tokenArrayValue
+			f.seg = f.seg.(int64) + 1
+		case tokenObjectStart:
+			if b == '}' {
+				return d.tokenEnd(b)
+			}
+			return d.tokenKey(b)
+		case tokenObjectKey:
+			return d.tokenKey(b)
+		case tokenObjectColon:
+			if b != ':' {
+				return nil, d.syntaxError(0, "invalid character %q after object key", b)
+			}
+			d.discard(1)
+			f.state = tokenObjectValue
+		case tokenObjectComma:
+			if b == '}' {
+				return d.tokenEnd(b)
+			}
+			if b != ',' {
+				return nil, d.syntaxError(0, "invalid character %q after object key:value pair", b)
+			}
+			d.discard(1)
+			f.state = tokenObjectKey
+		}
+	}
+}
+
+// tokenValue returns the start of an array or object or a scalar value.
+func (d *Decoder) tokenValue(b byte) (any, error) {
+	switch b {
+	case '[', '{':
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		if len(d.tokens) > 0 {
+			d.path = append(d.path, d.frame().seg)
+		}
+		d.discard(1)
+		if b == '[' {
+			d.tokens = append(d.tokens, tokenFrame{state: tokenArrayStart, seg: int64(0)})
+		} else {
+			d.tokens = append(d.tokens, tokenFrame{state: tokenObjectStart})
+		}
+		return Delim(b), nil
+	}
+	return d.elementValue(d.decodeValue, false)
+}
+
+// tokenEnd returns the end of the current array or object.
+func (d *Decoder) tokenEnd(b byte) (any, error) {
+	d.discard(1)
+	d.exit()
+	d.tokens = d.tokens[:len(d.tokens)-1]
+	if len(d.tokens) > 0 {
+		d.path = d.path[:len(d.path)-1]
+	}
+	if err := d.endValue(); err != nil {
+		return nil, err
+	}
+	return Delim(b), nil
+}
+
+func (d *Decoder) tokenKey(b byte) (any, error) {
+	if b != '"' {
+		return nil, d.syntaxError(0, "invalid character %q looking for beginning of object key string", b)
+	}
+	keyPos := d.pos()
+	k, err := d.decodeString()
+	if err != nil {
+		return nil, err
+	}
+	f := d.frame()
+	if d.opts.DisallowDuplicateKeys {
+		if _, ok := f.keys[string(k.(str))]; ok {
+			return nil, d.syntaxErrorAt(keyPos, 0, "duplicate object key %q", k)
+		}
+		if f.keys == nil {
+			f.keys = make(map[string]struct{})
+		}
+		f.keys[string(k.(str))] = struct{}{}
+	}
+	f.state = tokenObjectColon
+	f.seg = string(k.(str))
+	return Key(k.(str)), nil
+}
+
+// elementValue decodes the next value using fn and advances the state
+// of any array or object opened by Token. When skipKey is set and an
+// object key is expected, the key is consumed before the value.
+func (d *Decoder) elementValue(fn func() (any, error), skipKey bool) (any, error) {
+	if len(d.tokens) == 0 {
+		v, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		if err := d.endValue(); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	if err := d.skipWhitespace(); err != nil {
+		return nil, d.ioError(err)
+	}
+	f := d.frame()
+	b, err := d.peekByte()
+	if err != nil {
+		return nil, d.ioError(err)
+	}
+	switch f.state {
+	case tokenArrayComma:
+		if b != ',' {
+			return nil, d.syntaxError(0, "invalid character %q after array element", b)
+		}
+		d.discard(1)
+		f.state = tokenArrayValue
+		f.seg = f.seg.(int64) + 1
+	case tokenObjectColon:
+		if b != ':' {
+			return nil, d.syntaxError(0, "invalid character %q after object key", b)
+		}
+		d.discard(1)
+		f.state = tokenObjectValue
+	case tokenObjectStart, tokenObjectKey, tokenObjectComma:
+		if !skipKey {
+			return nil, fmt.Errorf("Decode: called when an object key is expected")
+		}
+		if f.state == tokenObjectComma {
+			if b != ',' {
+				return nil, d.syntaxError(0, "invalid character %q after object key:value pair", b)
+			}
+			d.discard(1)
+			f.state = tokenObjectKey
+			if err := d.skipWhitespace(); err != nil {
+				return nil, d.ioError(err)
+			}
+			if b, err = d.peekByte(); err != nil {
+				return nil, d.ioError(err)
+			}
+		}
+		if _, err := d.tokenKey(b); err != nil {
+			return nil, err
+		}
+		return d.elementValue(fn, skipKey)
+	}
+	d.path = append(d.path, f.seg)
+	v, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	d.path = d.path[:len(d.path)-1]
+	if err := d.endValue(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// endValue advances the token state after a complete value.
+func (d *Decoder) endValue() error {
+	if len(d.tokens) == 0 {
+		if d.opts.DisallowTrailingData {
+			return d.checkTrailingData()
+		}
+		return nil
+	}
+	switch f := d.frame(); f.state {
+	case tokenArrayStart, tokenArrayValue:
+		f.state = tokenArrayComma
+	case tokenObjectValue:
+		f.state = tokenObjectComma
+	}
+	return nil
+}
+
+// Skip skips over the next value in the input without decoding it.
+//
+// When an object key is expected, Skip skips both the key and its value.
+// Skip validates the structure of the skipped value but not the escape
+// sequences of skipped strings. Errors in the keys and values of skipped
+// objects report the path of the enclosing object.
+func (d *Decoder) Skip() error {
+	if len(d.tokens) == 0 {
+		d.path = d.path[:0]
+		d.depth = 0
+		if err := d.skipWhitespace(); err != nil {
+			return err
+		}
+	}
+	_, err := d.elementValue(func() (any, error) { return nil, d.skipValue() }, true)
+	return err
+}
+
+func (d *Decoder) skipValue() error {
+	if err := d.skipWhitespace(); err != nil {
+		return d.ioError(err)
+	}
+	b, err := d.peekByte()
+	if err != nil {
+		return d.ioError(err)
+	}
+	switch b {
+	case '"':
+		return d.skipString()
+	case '[':
+		return d.skipArray()
+	case '{':
+		return d.skipObject()
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return d.skipNumber()
+	default:
+		_, err := d.decodeValue()
+		return err
+	}
+}
+
+func (d *Decoder) skipNumber() error {
+	bs, err := d.r.Peek(d.r.Buffered())
+	if err != nil {
+		return err
+	}
+	n := 0
+	for ; n < len(bs) && isNumberByte(bs[n]); n++ {
+	}
+	if n == len(bs) {
+		// The number may continue past the buffered data.
+		_, err := d.decodeNumber()
+		return err
+	}
+	if !d.skippableNumber(bs[:n]) {
+		return d.syntaxError(0, "invalid numeric literal %q", bs[:n])
+	}
+	d.discard(n)
+	return nil
+}
+
+// skippableNumber reports whether decodeNumber accepts the literal b
+// with the Decoder's options. Like decodeNumber with UseNumber, it
+// checks the grammar but not whether b fits in a float64.
+func (d *Decoder) skippableNumber(b []byte) bool {
+	if validNumber(b) {
+		return true
+	}
+	if d.opts.StrictNumbers {
+		return false
+	}
+	// Lenient literals like "01" or "1." which ParseFloat accepts.
+	_, err := strconv.ParseFloat(string(b), 64)
+	return err == nil || errors.Is(err, strconv.ErrRange)
+}
+
+func isNumberByte(b byte) bool {
+	return '0' <= b && b <= '9' || b == '-' || b == '+' || b == '.' || b == 'e' || b == 'E'
+}
+
+func (d *Decoder) skipString() error {
+	start := d.pos()
+	d.discard(1) // '"'
+	escape := false
+	n := 0 // Bytes between the quotes.
+	for {
+		bs, err := d.r.ReadSlice('"')
+		d.advance(bs)
+		if err != nil && err != bufio.ErrBufferFull {
+			return d.ioError(err)
+		}
+		closed := false
+		for _, c := range bs {
+			if escape {
+				escape = false
+			} else if c == '\\' {
+				escape = true
+			} else if c == '"' {
+				closed = true
+			}
+		}
+		n += len(bs)
+		if closed {
+			n-- // Closing quote.
+		}
+		if max := d.opts.MaxStringLen; max > 0 && n > max {
+			return d.syntaxErrorAt(start, 0, "string literal exceeds max length of %d", max)
+		}
+		if closed {
+			return nil
+		}
+	}
+}
+
+func (d *Decoder) skipArray() error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.exit()
+	d.discard(1) // '['
+	for i := 0; ; i++ {
+		if err := d.skipWhitespace(); err != nil {
+			return d.ioError(err)
+		}
+		b, err := d.peekByte()
+		if err != nil {
+			return d.ioError(err)
+		}
+		if b == ']' {
+			d.discard(1)
+			return nil
+		}
+		if i > 0 {
+			if b != ',' {
+				return d.syntaxError(0, "invalid character %q after array element", b)
+			}
+			d.discard(1)
+		}
+		d.path = append(d.path, int64(i))
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+		d.path = d.path[:len(d.path)-1]
+	}
+}
+
+func (d *Decoder) skipObject() error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.exit()
+	d.discard(1) // '{'
+	for i := 0; ; i++ {
+		if err := d.skipWhitespace(); err != nil {
+			return d.ioError(err)
+		}
+		b, err := d.peekByte()
+		if err != nil {
+			return d.ioError(err)
+		}
+		if b == '}' {
+			d.discard(1)
+			return nil
+		}
+		if i > 0 {
+			if b != ',' {
+				return d.syntaxError(0, "invalid character %q after object key:value pair", b)
+			}
+			d.discard(1)
+			if err := d.skipWhitespace(); err != nil {
+				return d.ioError(err)
+			}
+			if b, err = d.peekByte(); err != nil {
+				return d.ioError(err)
+			}
+		}
+		if b != '"' {
+			return d.syntaxError(0, "invalid character %q looking for beginning of object key string", b)
+		}
+		if err := d.skipString(); err != nil {
+			return err
+		}
+		if err := d.skipWhitespace(); err != nil {
+			return d.ioError(err)
+		}
+		if b, err = d.peekByte(); err != nil {
+			return d.ioError(err)
+		}
+		if b != ':' {
+			return d.syntaxError(0, "invalid character %q after object key", b)
+		}
+		d.discard(1)
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+	}
+}
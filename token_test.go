@@ -0,0 +1,228 @@
+package jsong
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func readTokens(t *testing.T, d *Decoder) []any {
+	t.Helper()
+	var tokens []any
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return tokens
+		}
+		if err != nil {
+			t.Fatalf("Token(): got err = %v, want err = false", err)
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func TestToken(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a": [1, "b", null, true], "c": {}} [[]] 3`))
+
+	got := readTokens(t, d)
+
+	want := []any{
+		Delim('{'),
+		Key("a"),
+		Delim('['),
+		num(1),
+		str("b"),
+		null{},
+		boolean(true),
+		Delim(']'),
+		Key("c"),
+		Delim('{'),
+		Delim('}'),
+		Delim('}'),
+		Delim('['),
+		Delim('['),
+		Delim(']'),
+		Delim(']'),
+		num(3),
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Token(): got diff:\n%s", diff)
+	}
+}
+
+func TestTokenDecode(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`[{"a": 1}, {"a": 2}]`))
+
+	if tok, err := d.Token(); err != nil || tok != Delim('[') {
+		t.Fatalf("Token(): got %v, %v, want '['", tok, err)
+	}
+
+	var got []any
+	for i := 0; i < 2; i++ {
+		v, err := d.Decode()
+		if err != nil {
+			t.Fatalf("Decode(): got err = %v, want err = false", err)
+		}
+		got = append(got, v)
+	}
+
+	want := []any{object{"a": num(1)}, object{"a": num(2)}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Decode(): got diff:\n%s", diff)
+	}
+
+	if tok, err := d.Token(); err != nil || tok != Delim(']') {
+		t.Fatalf("Token(): got %v, %v, want ']'", tok, err)
+	}
+}
+
+func TestTokenSkip(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"skip": {"a": ["x\\\"]", {"b": [[]]}]}, "keep": [1], "skip2": "}"}`))
+
+	var got []any
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Token(): got err = %v, want err = false", err)
+		}
+		got = append(got, tok)
+		if k, ok := tok.(Key); ok && strings.HasPrefix(string(k), "skip") {
+			if err := d.Skip(); err != nil {
+				t.Fatalf("Skip(): got err = %v, want err = false", err)
+			}
+		}
+	}
+
+	want := []any{
+		Delim('{'),
+		Key("skip"),
+		Key("keep"),
+		Delim('['),
+		num(1),
+		Delim(']'),
+		Key("skip2"),
+		Delim('}'),
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Token(): got diff:\n%s", diff)
+	}
+}
+
+func TestTokenSkipKey(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a": [1, 2], "b": 3}`))
+
+	d.Token() // '{'
+	if err := d.Skip(); err != nil {
+		t.Fatalf("Skip(): got err = %v, want err = false", err)
+	}
+
+	got := readTokens(t, d)
+
+	want := []any{Key("b"), num(3), Delim('}')}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Token(): got diff:\n%s", diff)
+	}
+}
+
+func TestTokenSkipNumbers(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		opts    DecoderOptions
+		wantErr bool
+	}{
+		{input: `[-]`, wantErr: true},
+		{input: `[1e]`, wantErr: true},
+		{input: `[1-2,3]`, wantErr: true},
+		{input: `[1.2.3]`, wantErr: true},
+		{input: `[1e5e6]`, wantErr: true},
+		{input: `[-0.5e+3, 10]`},
+		{input: `[01]`},
+		{input: `[01]`, opts: DecoderOptions{StrictNumbers: true}, wantErr: true},
+		{input: `[1.]`, opts: DecoderOptions{UseNumber: true}},
+	} {
+		if _, err := tc.opts.NewDecoder(strings.NewReader(tc.input)).Decode(); (err != nil) != tc.wantErr {
+			t.Errorf("Decode(%q): got err = %v, want err = %v", tc.input, err, tc.wantErr)
+		}
+		d := tc.opts.NewDecoder(strings.NewReader(tc.input))
+		d.Token() // '['
+		if err := d.Skip(); (err != nil) != tc.wantErr {
+			t.Errorf("Skip(%q): got err = %v, want err = %v", tc.input, err, tc.wantErr)
+		}
+	}
+}
+
+func TestTokenSkipMaxStringLen(t *testing.T) {
+	// The strings are longer than the Decoder's 4096 byte buffer,
+	// so they are read across a refill of the buffer. The escaped
+	// quote lands on either side of the boundary.
+	const max = 4100
+	opts := DecoderOptions{MaxStringLen: max}
+	for _, tc := range []struct {
+		name    string
+		content string
+		wantErr bool
+	}{{
+		name:    "max length",
+		content: strings.Repeat("a", max),
+	}, {
+		name:    "max length escaped quote",
+		content: strings.Repeat("a", max-2) + `\"`,
+	}, {
+		name:    "max length exceeded",
+		content: strings.Repeat("a", max+1),
+		wantErr: true,
+	}, {
+		name:    "max length exceeded escaped quote",
+		content: strings.Repeat("a", max-1) + `\"`,
+		wantErr: true,
+	}} {
+		for pad := 4090; pad < 4100; pad++ {
+			input := "[" + strings.Repeat(" ", pad) + `"` + tc.content + `"]`
+			if _, err := opts.NewDecoder(iotest.OneByteReader(strings.NewReader(input))).Decode(); (err != nil) != tc.wantErr {
+				t.Errorf("Decode(%s, pad=%d): got err = %v, want err = %v", tc.name, pad, err, tc.wantErr)
+			}
+			d := opts.NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+			d.Token() // '['
+			if err := d.Skip(); (err != nil) != tc.wantErr {
+				t.Errorf("Skip(%s, pad=%d): got err = %v, want err = %v", tc.name, pad, err, tc.wantErr)
+			}
+		}
+	}
+}
+
+func TestTokenSyntaxError(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a": [1 2]}`))
+
+	var err error
+	for err == nil {
+		_, err = d.Token()
+	}
+
+	var got *SyntaxError
+	if !errors.As(err, &got) {
+		t.Fatalf("Token(): want *SyntaxError, got err = %v", err)
+	}
+
+	want := &SyntaxError{
+		msg:    `invalid character '2' after array element`,
+		Offset: 9,
+		Line:   1,
+		Column: 10,
+		Path:   "a",
+	}
+
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(SyntaxError{})); diff != "" {
+		t.Errorf("Token(): got diff:\n%s", diff)
+	}
+}
@@ -1,18 +1,103 @@
 package jsong
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
-	"strings"
+	"slices"
+	"strconv"
+	"sync"
+
+	"golang.org/x/exp/maps"
 )
 
 var (
 	booleanType = reflect.TypeOf(boolean(false))
 	numType     = reflect.TypeOf(num(0))
 	strType     = reflect.TypeOf(str(""))
-	stringType  = reflect.TypeOf(string(""))
+	numberType  = reflect.TypeOf(json.Number(""))
+
+	structType = reflect.TypeFor[Struct]()
+
+	valuerType        = reflect.TypeFor[Valuer]()
+	marshalerType     = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Valuer is implemented by types which convert themselves to jsong values
+// without reflection such as the types with methods generated by jsong gen.
+// ValueOf converts the result of JSONGValue in place of the value which must
+// give the same jsong value as the value would.
+type Valuer interface {
+	JSONGValue() any
+}
+
+// Struct is a Go struct given by its fields in declaration order.
+// It is returned by the JSONGValue methods generated by jsong gen.
+// ValueOf converts a Struct to an object like the struct itself.
+type Struct []StructField
+
+// StructField is the field of a Struct.
+type StructField struct {
+	Name   string
+	Value  any
+	Quoted bool // Whether the value is encoded inside a string.
+}
+
+// UnsupportedPolicy controls the handling of Go values
+// of types which have no jsong value.
+type UnsupportedPolicy uint8
+
+const (
+	// UnsupportedError fails with an *UnsupportedValueError.
+	UnsupportedError UnsupportedPolicy = iota
+	// UnsupportedNull converts unsupported values to null.
+	UnsupportedNull
+	// UnsupportedOmit omits unsupported struct fields and map entries.
+	// Unsupported array elements are converted to null.
+	UnsupportedOmit
 )
 
+// ValueOptions configure how Go values are converted by ValueOf.
+type ValueOptions struct {
+	// ExactNumbers converts integers and json.Number values
+	// to exact numbers instead of float64.
+	ExactNumbers bool
+
+	// OrderedObjects converts structs to objects which keep their fields
+	// in declaration order. Maps are converted with their keys sorted.
+	OrderedObjects bool
+
+	// Unsupported controls the handling of channels, functions, complex
+	// numbers, unsafe pointers and maps with unsupported key types.
+	// Cycles and errors from marshal methods always fail.
+	Unsupported UnsupportedPolicy
+}
+
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrCycle           = errors.New("encountered a cycle")
+)
+
+// UnsupportedValueError describes a Go value which cannot be converted.
+type UnsupportedValueError struct {
+	Type reflect.Type // Type of the Go value.
+	Path string       // Path of the value as used by Extract.
+	Err  error        // Reason such as ErrUnsupportedType or ErrCycle.
+}
+
+func (e *UnsupportedValueError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("ValueOf: unsupported value of type %s: %v", e.Type, e.Err)
+	}
+	return fmt.Sprintf("ValueOf: unsupported value of type %s at %q: %v", e.Type, e.Path, e.Err)
+}
+
+func (e *UnsupportedValueError) Unwrap() error { return e.Err }
+
 // ValueOf creates the jsong value of the input v.
 //
 // It performs an operation similar to, but more
@@ -22,16 +107,77 @@ var (
 //	v := new(any)
 //	json.Unmarshal(data, v)
 //
-// ValueOf panics if v contains any cycles.
+// Types implementing json.Marshaler or encoding.TextMarshaler are
+// converted using their methods like in encoding/json, including the
+// pointer methods of addressable values, and TextMarshaler map keys.
+// Types implementing Valuer are converted using JSONGValue first.
+// JSONGValue methods with pointer receivers, like the generated ones,
+// also convert values which are not addressable using an addressable
+// copy unless the value would convert differently when addressable.
+//
+// ValueOf panics with the error ValueOfErr would return.
+//
+// Other functions of this package taking Go values without returning
+// an error don't panic. They treat values which ValueOfErr cannot
+// convert, such as cyclic values and funcs, as missing like nil
+// pointers: Extract, Delete, Clone and the other functions returning
+// values return nil and reducers ignore them.
 func ValueOf(v any) any {
+	return ValueOptions{}.ValueOf(v)
+}
+
+// ValueOfErr is like ValueOf but returns an error for values which
+// cannot be converted: an *UnsupportedValueError for unsupported
+// types and cycles or the error of a marshal method.
+func ValueOfErr(v any) (any, error) {
+	return ValueOptions{}.ValueOfErr(v)
+}
+
+// ValueOf creates the jsong value of the input v with the options o.
+// It panics with the error ValueOfErr would return.
+func (o ValueOptions) ValueOf(v any) any {
+	res, err := o.ValueOfErr(v)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// ValueOfErr creates the jsong value of the input v with the options o.
+func (o ValueOptions) ValueOfErr(v any) (any, error) {
 	if _, ok := v.(valueInterface); ok {
 		// No need to re-encode valueInterface.
+		return v, nil
+	}
+	res, err := (&encoder{opts: o}).encode(reflect.ValueOf(v))
+	if err == errOmit {
+		return null{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// valueOf returns the jsong value of v for functions which do not
+// return errors. It returns nil for nil pointers and for values which
+// ValueOfErr cannot convert.
+func valueOf(v any) valueInterface {
+	if v, ok := v.(valueInterface); ok {
 		return v
 	}
-	return new(encoder).encode(reflect.ValueOf(v))
+	res, err := ValueOfErr(v)
+	if err != nil {
+		return nil
+	}
+	rv, _ := res.(valueInterface)
+	return rv
 }
 
 type encoder struct {
+	opts ValueOptions
+	path []any // Path of the value used in errors.
+
 	// Avoid cycles.
 	// See pkg.go.dev/encoding/json#encodeState for details.
 	ptrLevel uint
@@ -40,16 +186,44 @@ type encoder struct {
 
 const startDetectingCyclesAfter = 100
 
-func (e *encoder) encode(v reflect.Value) valueInterface {
+// errOmit is returned by encode for values omitted by UnsupportedOmit.
+var errOmit = errors.New("omit")
+
+// unsupported returns the error for the value v
+// or the replacement for unsupported types.
+func (e *encoder) unsupported(v reflect.Value, err error) (valueInterface, error) {
+	if errors.Is(err, ErrUnsupportedType) {
+		switch e.opts.Unsupported {
+		case UnsupportedNull:
+			return null{}, nil
+		case UnsupportedOmit:
+			return nil, errOmit
+		}
+	}
+	return nil, &UnsupportedValueError{Type: v.Type(), Path: JoinKey("", e.path...), Err: err}
+}
+
+func (e *encoder) encode(v reflect.Value) (valueInterface, error) {
+	if m, ok := marshalerOf(v); ok {
+		return e.encodeMarshaler(v, m)
+	}
+	if v.IsValid() && v.Type() == numberType {
+		return e.encodeNumber(v)
+	}
+	if v.IsValid() && v.Type() == structType && v.CanInterface() {
+		return e.encodeFields(v.Interface().(Struct))
+	}
 	switch v.Kind() {
 	case reflect.Bool:
-		return v.Convert(booleanType).Interface().(boolean)
-	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
-		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint,
-		reflect.Float32, reflect.Float64:
-		return v.Convert(numType).Interface().(valueInterface)
+		return v.Convert(booleanType).Interface().(boolean), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return e.encodeInt(v.Int()), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return e.encodeUint(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Convert(numType).Interface().(valueInterface), nil
 	case reflect.String:
-		return v.Convert(strType).Interface().(valueInterface)
+		return v.Convert(strType).Interface().(valueInterface), nil
 	case reflect.Array:
 		return e.encodeArray(v)
 	case reflect.Slice:
@@ -63,131 +237,465 @@ func (e *encoder) encode(v reflect.Value) valueInterface {
 	case reflect.Pointer:
 		return e.encodePointer(v)
 	case reflect.Invalid:
-		return null{}
+		return null{}, nil
+	default:
+		return e.unsupported(v, ErrUnsupportedType)
+	}
+}
+
+// marshalerMethods records the marshaler interfaces implemented
+// by a type and by a pointer to it.
+type marshalerMethods uint8
+
+const (
+	hasValuer marshalerMethods = 1 << iota
+	hasPtrValuer
+	hasMarshaler
+	hasPtrMarshaler
+	hasTextMarshaler
+	hasPtrTextMarshaler
+	hasCopyValuer // The pointer Valuer converts copies of values.
+)
+
+var marshalerCache sync.Map // map[reflect.Type]marshalerMethods
+
+// typeMarshalerMethods returns the marshaler methods of t using a cache.
+func typeMarshalerMethods(t reflect.Type) marshalerMethods {
+	if m, ok := marshalerCache.Load(t); ok {
+		return m.(marshalerMethods)
+	}
+	var m marshalerMethods
+	if t.Implements(valuerType) {
+		m |= hasValuer
+	}
+	if t.Implements(marshalerType) {
+		m |= hasMarshaler
+	}
+	if t.Implements(textMarshalerType) {
+		m |= hasTextMarshaler
+	}
+	if t.Kind() != reflect.Pointer {
+		pt := reflect.PointerTo(t)
+		if pt.Implements(valuerType) {
+			m |= hasPtrValuer
+		}
+		if pt.Implements(marshalerType) {
+			m |= hasPtrMarshaler
+		}
+		if pt.Implements(textMarshalerType) {
+			m |= hasPtrTextMarshaler
+		}
+	}
+	if m == hasPtrValuer && !contentsAddrSensitive(t) {
+		// Values which are not addressable convert like their
+		// addressable copies so the pointer Valuer converts them.
+		m |= hasCopyValuer
+	}
+	marshalerCache.Store(t, m)
+	return m
+}
+
+// ptrOnlyMarshaler reports whether json.Marshaler or
+// encoding.TextMarshaler is implemented only by the pointer.
+// Valuers give the same values either way.
+func (m marshalerMethods) ptrOnlyMarshaler() bool {
+	return m&hasPtrMarshaler != 0 && m&hasMarshaler == 0 ||
+		m&hasPtrTextMarshaler != 0 && m&hasTextMarshaler == 0
+}
+
+// addrSensitive reports whether values of type t convert differently
+// when they are addressable because t or the fields and elements it
+// contains have marshal methods only on their pointers.
+func addrSensitive(t reflect.Type) bool {
+	return typeMarshalerMethods(t).ptrOnlyMarshaler() || contentsAddrSensitive(t)
+}
+
+// contentsAddrSensitive is like addrSensitive for the struct fields
+// or array elements of t. Pointers, slices and maps are addressable
+// or not regardless of the value containing them.
+func contentsAddrSensitive(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if addrSensitive(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Array:
+		return addrSensitive(t.Elem())
+	}
+	return false
+}
+
+// marshalerOf returns the Valuer, json.Marshaler or encoding.TextMarshaler
+// of v in the same order of precedence as encoding/json after Valuer.
+// Nil pointers and interfaces are not marshalers.
+func marshalerOf(v reflect.Value) (any, bool) {
+	if !v.IsValid() || v.Kind() == reflect.Interface {
+		return nil, false
+	}
+	m := typeMarshalerMethods(v.Type())
+	if m == 0 || !v.CanInterface() {
+		return nil, false
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, false
+	}
+	addr := v.CanAddr()
+	switch {
+	case addr && m&hasPtrValuer != 0:
+		return v.Addr().Interface(), true
+	case m&hasValuer != 0:
+		return v.Interface(), true
+	case m&hasCopyValuer != 0:
+		c := reflect.New(v.Type())
+		c.Elem().Set(v)
+		return c.Interface(), true
+	case addr && m&hasPtrMarshaler != 0:
+		return v.Addr().Interface(), true
+	case m&hasMarshaler != 0:
+		return v.Interface(), true
+	case addr && m&hasPtrTextMarshaler != 0:
+		return v.Addr().Interface(), true
+	case m&hasTextMarshaler != 0:
+		return v.Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+func (e *encoder) encodeMarshaler(v reflect.Value, m any) (valueInterface, error) {
+	if m, ok := m.(Valuer); ok {
+		return e.encodeValuer(v, m)
+	}
+	if m, ok := m.(json.Marshaler); ok {
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return e.unsupported(v, fmt.Errorf("error calling MarshalJSON: %w", err))
+		}
+		res, err := DecoderOptions{
+			DisallowTrailingData: true,
+			StrictNumbers:        true,
+			UseNumber:            e.opts.ExactNumbers,
+			OrderedObjects:       e.opts.OrderedObjects,
+		}.NewDecoder(bytes.NewReader(b)).Decode()
+		if err != nil {
+			return e.unsupported(v, fmt.Errorf("error calling MarshalJSON: %w", err))
+		}
+		return res.(valueInterface), nil
+	}
+	b, err := m.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return e.unsupported(v, fmt.Errorf("error calling MarshalText: %w", err))
+	}
+	return str(b), nil
+}
+
+func (e *encoder) encodeValuer(v reflect.Value, m Valuer) (valueInterface, error) {
+	if v.Kind() == reflect.Pointer {
+		// Detect cycles through JSONGValue like encodePointer.
+		if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+			ptr := v.Interface()
+			if err := e.enter(v, ptr); err != nil {
+				return nil, err
+			}
+			defer func() { e.ptrLevel--; delete(e.ptrSeen, ptr) }()
+		}
+	}
+	return e.encodeAny(m.JSONGValue())
+}
+
+// encodeAny encodes the Go value x avoiding reflection for common types.
+// Like fields of interface type, a nil x is encoded as a nil value.
+func (e *encoder) encodeAny(x any) (valueInterface, error) {
+	switch x := x.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return str(x), nil
+	case bool:
+		return boolean(x), nil
+	case float64:
+		return num(x), nil
+	case float32:
+		return num(x), nil
+	case int:
+		return e.encodeInt(int64(x)), nil
+	case int8:
+		return e.encodeInt(int64(x)), nil
+	case int16:
+		return e.encodeInt(int64(x)), nil
+	case int32:
+		return e.encodeInt(int64(x)), nil
+	case int64:
+		return e.encodeInt(x), nil
+	case uint:
+		return e.encodeUint(uint64(x)), nil
+	case uint8:
+		return e.encodeUint(uint64(x)), nil
+	case uint16:
+		return e.encodeUint(uint64(x)), nil
+	case uint32:
+		return e.encodeUint(uint64(x)), nil
+	case uint64:
+		return e.encodeUint(x), nil
+	case Struct:
+		return e.encodeFields(x)
 	default:
-		panic(fmt.Errorf("ValueOf: unsupported Kind: %q", v.Kind()))
+		return e.encode(reflect.ValueOf(x))
+	}
+}
+
+func (e *encoder) encodeInt(i int64) valueInterface {
+	if e.opts.ExactNumbers {
+		return decimal(strconv.FormatInt(i, 10))
+	}
+	return num(i)
+}
+
+func (e *encoder) encodeUint(u uint64) valueInterface {
+	if e.opts.ExactNumbers {
+		return decimal(strconv.FormatUint(u, 10))
 	}
+	return num(u)
 }
 
-func (e *encoder) encodeInterface(v reflect.Value) valueInterface {
+func (e *encoder) encodeNumber(v reflect.Value) (valueInterface, error) {
+	s := v.String()
+	if s == "" {
+		// Same as encoding/json.
+		s = "0"
+	}
+	if !validNumber([]byte(s)) {
+		return e.unsupported(v, fmt.Errorf("invalid number literal %q", s))
+	}
+	if e.opts.ExactNumbers {
+		return decimal(s), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return e.unsupported(v, err)
+	}
+	return num(f), nil
+}
+
+func (e *encoder) encodeInterface(v reflect.Value) (valueInterface, error) {
 	if v.IsNil() {
-		return nil
+		return nil, nil
 	}
 	return e.encode(v.Elem())
 }
 
-func (e *encoder) encodePointer(v reflect.Value) valueInterface {
+// enter records the pointer ptr of the value v
+// and reports an error if it was seen before.
+func (e *encoder) enter(v reflect.Value, ptr any) error {
+	if _, ok := e.ptrSeen[ptr]; ok {
+		return &UnsupportedValueError{Type: v.Type(), Path: JoinKey("", e.path...), Err: ErrCycle}
+	}
+	if e.ptrSeen == nil {
+		e.ptrSeen = make(map[any]struct{})
+	}
+	e.ptrSeen[ptr] = struct{}{}
+	return nil
+}
+
+func (e *encoder) encodePointer(v reflect.Value) (valueInterface, error) {
 	if v.IsNil() {
-		return nil
+		return nil, nil
 	}
 	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
 		ptr := v.Interface()
-		if _, ok := e.ptrSeen[ptr]; ok {
-			panic(fmt.Errorf("ValueOf: encountered a cycle via %s", v.Type()))
-		}
-		if e.ptrSeen == nil {
-			e.ptrSeen = make(map[any]struct{})
+		if err := e.enter(v, ptr); err != nil {
+			return nil, err
 		}
-		e.ptrSeen[ptr] = struct{}{}
 		defer func() { e.ptrLevel--; delete(e.ptrSeen, ptr) }()
 	}
 	return e.encode(v.Elem())
 }
 
-func (e *encoder) encodeArray(v reflect.Value) valueInterface {
-	if v.IsNil() {
-		return array(nil)
+func (e *encoder) encodeArray(v reflect.Value) (valueInterface, error) {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return array(nil), nil
 	}
 	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
 		// Special case for []byte.
-		return v.Convert(strType).Interface().(valueInterface)
+		return v.Convert(strType).Interface().(valueInterface), nil
 	}
-	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+	// Go arrays are values which cannot form cycles themselves.
+	if e.ptrLevel++; v.Kind() == reflect.Slice && e.ptrLevel > startDetectingCyclesAfter {
 		ptr := struct {
 			ptr interface{}
 			len int
 		}{v.UnsafePointer(), v.Len()}
-		if _, ok := e.ptrSeen[ptr]; ok {
-			panic(fmt.Errorf("ValueOf: encountered a cycle via %s", v.Type()))
-		}
-		if e.ptrSeen == nil {
-			e.ptrSeen = make(map[any]struct{})
+		if err := e.enter(v, ptr); err != nil {
+			return nil, err
 		}
-		e.ptrSeen[ptr] = struct{}{}
 		defer func() { e.ptrLevel--; delete(e.ptrSeen, ptr) }()
 	}
 	res := make(array, v.Len())
 	for i := 0; i < v.Len(); i++ {
-		res[i] = e.encode(v.Index(i))
+		e.path = append(e.path, int64(i))
+		elem, err := e.encode(v.Index(i))
+		if err == errOmit {
+			elem, err = null{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.path = e.path[:len(e.path)-1]
+		res[i] = elem
 	}
-	return res
+	return res, nil
 }
 
-func (e *encoder) encodeMap(v reflect.Value) valueInterface {
-	if v.IsNil() {
-		return object(nil)
+func (e *encoder) encodeMap(v reflect.Value) (valueInterface, error) {
+	if !validMapKey(v.Type().Key()) {
+		return e.unsupported(v, fmt.Errorf("%w: map key type %s", ErrUnsupportedType, v.Type().Key()))
 	}
-	if !v.Type().Key().ConvertibleTo(stringType) {
-		panic(fmt.Errorf("ValueOf: map keys must be convertable to string"))
+	if v.IsNil() {
+		return object(nil), nil
 	}
 	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
 		ptr := v.UnsafePointer()
-		if _, ok := e.ptrSeen[ptr]; ok {
-			panic(fmt.Errorf("ValueOf: encountered a cycle via %s", v.Type()))
+		if err := e.enter(v, ptr); err != nil {
+			return nil, err
 		}
-		if e.ptrSeen == nil {
-			e.ptrSeen = make(map[any]struct{})
-		}
-		e.ptrSeen[ptr] = struct{}{}
 		defer func() { e.ptrLevel--; delete(e.ptrSeen, ptr) }()
 	}
 	iter := v.MapRange()
 	res := make(object, v.Len())
 	for iter.Next() {
-		k := iter.Key().Convert(stringType).Interface().(string)
-		res[k] = e.encode(iter.Value())
+		k, err := e.mapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		e.path = append(e.path, k)
+		elem, err := e.encode(iter.Value())
+		if err == errOmit {
+			e.path = e.path[:len(e.path)-1]
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.path = e.path[:len(e.path)-1]
+		res[k] = elem
 	}
-	return res
+	if e.opts.OrderedObjects {
+		keys := maps.Keys(res)
+		slices.Sort(keys)
+		return &orderedObject{keys: keys, m: res}, nil
+	}
+	return res, nil
 }
 
-func (d *encoder) encodeStruct(rv reflect.Value) valueInterface {
-	t := rv.Type()
-	res := make(object, t.NumField())
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		name := f.Name
-		var omitEmpty bool
-		if jsonTag, ok := f.Tag.Lookup("json"); ok {
-			omitEmpty = strings.HasSuffix(jsonTag, ",omitempty")
-			name = strings.TrimSuffix(jsonTag, ",omitempty")
-		}
-		if name == "-" {
-			continue // Skip no JSON.
-		}
-		v := d.encode(rv.Field(i))
-		if name == "" && f.Anonymous {
-			vm := v.(object)
-			// Embed resulting values into the map directly
-			// While avoiding collisions.
-			var toDeleteKeys []string
-			for k, v := range vm {
-				if _, ok := res[k]; !ok {
-					res[k] = v
-					toDeleteKeys = append(toDeleteKeys, k)
-				}
-			}
-			for _, k := range toDeleteKeys {
-				delete(vm, k)
-			}
-			if len(vm) > 0 {
-				// Any collision keys are stored in the original place.
-				res[f.Name] = vm
-			}
+// validMapKey reports whether map keys of type t can be
+// converted to object keys like in encoding/json.
+func validMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return true
+	default:
+		return typeMarshalerMethods(t)&hasTextMarshaler != 0
+	}
+}
+
+// mapKey returns the object key of the map key k.
+func (e *encoder) mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if typeMarshalerMethods(k.Type())&hasTextMarshaler != 0 {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			_, err = e.unsupported(k, fmt.Errorf("error calling MarshalText: %w", err))
+			return "", err
+		}
+		return string(b), nil
+	}
+	switch k.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return strconv.FormatInt(k.Int(), 10), nil
+	default:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+}
+
+// newObject returns an empty object for n entries.
+func (e *encoder) newObject(n int) valueInterface {
+	if e.opts.OrderedObjects {
+		return newOrderedObject(n)
+	}
+	return make(object, n)
+}
+
+func (d *encoder) encodeStruct(rv reflect.Value) (valueInterface, error) {
+	fields := cachedTypeFields(rv.Type())
+	res := d.newObject(len(fields))
+	for i := range fields {
+		f := &fields[i]
+		fv, ok := fieldValue(rv, f)
+		if !ok {
+			continue // Promoted through a nil pointer.
+		}
+		if f.omitEmpty && isEmptyValue(fv) || f.omitZero && isZeroValue(fv) {
 			continue
 		}
-		if !omitEmpty || !reflect.ValueOf(v).IsZero() {
-			res[name] = v
+		d.path = append(d.path, f.name)
+		v, err := d.encode(fv)
+		if err == errOmit {
+			d.path = d.path[:len(d.path)-1]
+			continue
 		}
+		if err != nil {
+			return nil, err
+		}
+		d.path = d.path[:len(d.path)-1]
+		if f.quoted {
+			v = quoteValue(v)
+		}
+		res.Put(f.name, v)
+	}
+	return res, nil
+}
+
+func (e *encoder) encodeFields(s Struct) (valueInterface, error) {
+	res := e.newObject(len(s))
+	for _, f := range s {
+		e.path = append(e.path, f.Name)
+		v, err := e.encodeAny(f.Value)
+		if err == errOmit {
+			e.path = e.path[:len(e.path)-1]
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.path = e.path[:len(e.path)-1]
+		if f.Quoted {
+			v = quoteValue(v)
+		}
+		res.Put(f.Name, v)
+	}
+	return res, nil
+}
+
+// quoteValue returns the scalar v encoded inside a string
+// for fields with the string option.
+func quoteValue(v valueInterface) valueInterface {
+	switch v := v.(type) {
+	case str:
+		// Same as encoding/json which escapes HTML in the quoted string.
+		return str(appendString(nil, string(v), true))
+	case boolean, num, decimal:
+		b, _ := new(Encoder).appendValue(nil, v, 0)
+		return str(b)
+	default:
+		return v
 	}
-	return res
 }
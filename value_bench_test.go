@@ -3,6 +3,7 @@ package jsong
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 var benchValueOfTestCases = []any{
@@ -30,6 +31,60 @@ func BenchmarkValueOf(b *testing.B) {
 	}
 }
 
+type benchEvent struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Timestamp time.Time         `json:"timestamp"`
+	User      *benchUser        `json:"user,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Count     int               `json:"count,string"`
+	Score     float64           `json:"score"`
+	Internal  string            `json:"-"`
+}
+
+type benchUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Admin bool   `json:"admin"`
+}
+
+var benchEventValue = &benchEvent{
+	ID:        "4f0c8e2a",
+	Type:      "click",
+	Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	User:      &benchUser{Name: "gopher", Email: "gopher@example.com"},
+	Tags:      []string{"a", "b", "c"},
+	Labels:    map[string]string{"env": "prod"},
+	Count:     3,
+	Score:     0.5,
+}
+
+func BenchmarkValueOfStruct(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ValueOf(benchEventValue)
+	}
+}
+
+func BenchmarkValueOfStructParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ValueOf(benchEventValue)
+		}
+	})
+}
+
+func BenchmarkValueOfStructBaseline(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := fallbackValueOf(benchEventValue); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func fallbackValueOf(v any) (any, error) {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -1,7 +1,15 @@
 package jsong
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -91,3 +99,365 @@ func TestValueOfMapCycle(t *testing.T) {
 
 	ValueOf(a)
 }
+
+func TestValueOfGoArray(t *testing.T) {
+	got := ValueOf(make([][1]int, startDetectingCyclesAfter+1))
+
+	want := make(array, startDetectingCyclesAfter+1)
+	for i := range want {
+		want[i] = array{num(0)}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ValueOf(): got diff:\n%s", diff)
+	}
+}
+
+// jsonValueOf returns the value of x encoded by encoding/json.
+func jsonValueOf(t *testing.T, x any) any {
+	t.Helper()
+	data, err := json.Marshal(x)
+	if err != nil {
+		t.Fatalf("json.Marshal(): got err: %v", err)
+	}
+	v, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("Decode(): got err = %v, want err = false", err)
+	}
+	return v
+}
+
+type ptrMarshaler struct{ V string }
+
+func (m *ptrMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"ptr":` + strings.ToUpper(`"`+m.V+`"`) + `}`), nil
+}
+
+type valueMarshaler struct{ V int }
+
+func (m valueMarshaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int{m.V, m.V})
+}
+
+type textKey struct{ A, B string }
+
+func (k textKey) MarshalText() ([]byte, error) { return []byte(k.A + "-" + k.B), nil }
+
+type errMarshaler struct{}
+
+func (errMarshaler) MarshalJSON() ([]byte, error) { return nil, errors.New("failed") }
+
+type marshalersStruct struct {
+	Time    time.Time
+	IP      net.IP
+	Big     big.Int
+	BigPtr  *big.Int
+	Raw     json.RawMessage
+	NilRaw  json.RawMessage
+	Ptr     ptrMarshaler
+	Value   valueMarshaler
+	Text    textKey
+	TextMap map[textKey]int
+	IntMap  map[int]bool
+	UintMap map[uint8]string
+}
+
+func TestValueOfMarshalers(t *testing.T) {
+	x := &marshalersStruct{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		IP:      net.IPv4(127, 0, 0, 1),
+		BigPtr:  new(big.Int).Lsh(big.NewInt(1), 100),
+		Raw:     json.RawMessage(`{"b":[1,2],"a":null}`),
+		Ptr:     ptrMarshaler{"x"},
+		Value:   valueMarshaler{2},
+		Text:    textKey{"a", "b"},
+		TextMap: map[textKey]int{{"c", "d"}: 1},
+		IntMap:  map[int]bool{-65: true},
+		UintMap: map[uint8]string{65: "A"},
+	}
+	x.Big.SetInt64(42)
+
+	got := ValueOf(x)
+
+	if diff := cmp.Diff(jsonValueOf(t, x), got); diff != "" {
+		t.Errorf("ValueOf(): got diff:\n%v", diff)
+	}
+	if got, _ := Number(Extract(got, "Big")); got != "42" {
+		t.Errorf("ValueOf(): got Big = %v, want 42", got)
+	}
+}
+
+func TestValueOfMarshalersNotAddressable(t *testing.T) {
+	// Pointer methods are not used when the value is not addressable.
+	x := struct{ Ptr ptrMarshaler }{ptrMarshaler{"x"}}
+
+	got := ValueOf(x)
+
+	if diff := cmp.Diff(jsonValueOf(t, x), got); diff != "" {
+		t.Errorf("ValueOf(): got diff:\n%v", diff)
+	}
+}
+
+func TestValueOfMarshalerExactNumbers(t *testing.T) {
+	x := new(big.Int).Lsh(big.NewInt(1), 100)
+
+	got := ValueOptions{ExactNumbers: true}.ValueOf(x)
+
+	if diff := cmp.Diff(decimal(x.String()), got); diff != "" {
+		t.Errorf("ValueOf(): got diff:\n%v", diff)
+	}
+}
+
+func TestValueOfMarshalerError(t *testing.T) {
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("ValueOf(): want panic = true, got panic = false")
+		}
+	}()
+
+	ValueOf([]any{errMarshaler{}})
+}
+
+func TestValueOfUnsupportedMapKey(t *testing.T) {
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("ValueOf(): want panic = true, got panic = false")
+		}
+	}()
+
+	ValueOf(map[float64]int{})
+}
+
+type unsupportedStruct struct {
+	A    int
+	Fn   func()
+	Ch   chan int
+	C    complex128
+	Arr  []any
+	Keys map[float64]int
+}
+
+func newUnsupportedStruct() unsupportedStruct {
+	return unsupportedStruct{A: 1, Fn: func() {}, Arr: []any{1, make(chan int)}, Keys: map[float64]int{1: 1}}
+}
+
+func TestValueOfErrUnsupported(t *testing.T) {
+	_, err := ValueOfErr(map[string]any{"a": []any{1, struct{ F func() }{}}})
+
+	var gotErr *UnsupportedValueError
+	if !errors.As(err, &gotErr) {
+		t.Fatalf("ValueOfErr(): got err = %v, want *UnsupportedValueError", err)
+	}
+	if gotErr.Path != "a.1.F" || gotErr.Type.String() != "func()" || !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("ValueOfErr(): got err = %v, want unsupported func() at a.1.F", err)
+	}
+}
+
+func TestValueOfErrMapKey(t *testing.T) {
+	_, err := ValueOfErr(struct{ M map[[2]int]int }{})
+
+	var gotErr *UnsupportedValueError
+	if !errors.As(err, &gotErr) || gotErr.Path != "M" || !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("ValueOfErr(): got err = %v, want unsupported map key at M", err)
+	}
+}
+
+func TestValueOfErrCycle(t *testing.T) {
+	a := map[string]any{}
+	a["b"] = []any{a}
+
+	_, err := ValueOfErr(a)
+
+	if !errors.Is(err, ErrCycle) {
+		t.Errorf("ValueOfErr(): got err = %v, want ErrCycle", err)
+	}
+}
+
+func TestValueOfErrMarshaler(t *testing.T) {
+	_, err := ValueOfErr(map[string]any{"a": errMarshaler{}})
+
+	var gotErr *UnsupportedValueError
+	if !errors.As(err, &gotErr) || gotErr.Path != "a" || errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("ValueOfErr(): got err = %v, want MarshalJSON error at a", err)
+	}
+}
+
+func TestValueOfUnsupportedPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy UnsupportedPolicy
+		want   any
+	}{{
+		policy: UnsupportedNull,
+		want: object{
+			"A":    num(1),
+			"Fn":   null{},
+			"Ch":   null{},
+			"C":    null{},
+			"Arr":  array{num(1), null{}},
+			"Keys": null{},
+		},
+	}, {
+		policy: UnsupportedOmit,
+		want: object{
+			"A":   num(1),
+			"Arr": array{num(1), null{}},
+		},
+	}} {
+		got, err := ValueOptions{Unsupported: tc.policy}.ValueOfErr(newUnsupportedStruct())
+		if err != nil {
+			t.Fatalf("ValueOfErr(%d): got err = %v, want err = false", tc.policy, err)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ValueOfErr(%d): got diff:\n%v", tc.policy, diff)
+		}
+	}
+}
+
+func TestValueOfUnsupportedEntryPoints(t *testing.T) {
+	v := newUnsupportedStruct()
+
+	if got := Extract(v, "A"); got != nil {
+		t.Errorf("Extract(): got %v, want nil", got)
+	}
+	if got := GlobKey(v, "Arr.*"); len(got) != 0 {
+		t.Errorf("GlobKey(): got %v, want no keys", got)
+	}
+	if (ObjectFieldFilter{"Fn": {}}).Filter(v) {
+		t.Errorf("Filter(): got true, want false")
+	}
+	if got := Compare(v, v); got != 0 {
+		t.Errorf("Compare(): got %d, want 0", got)
+	}
+	if err := Visit(v, func(string, any) error { return nil }); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Visit(): got err = %v, want ErrUnsupportedType", err)
+	}
+	if err := NewEncoder(io.Discard).Encode(v); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Encode(): got err = %v, want ErrUnsupportedType", err)
+	}
+	if err := Into(v, new(any)); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Into(): got err = %v, want ErrUnsupportedType", err)
+	}
+}
+
+type cyclicStruct struct {
+	A    int
+	Next *cyclicStruct
+}
+
+func newCyclicStruct() *cyclicStruct {
+	c := &cyclicStruct{A: 1}
+	c.Next = c
+	return c
+}
+
+func TestValueOfCycleEntryPoints(t *testing.T) {
+	v := newCyclicStruct()
+	jp := Must(CompileJSONPath("$..A"))
+	reducer := ObjectReducer{"A": &AnyReducer{V: num(1)}}
+
+	for _, tc := range []struct {
+		name string
+		fn   func() any
+		want any
+	}{
+		{name: "Extract", fn: func() any { return Extract(v, "A") }},
+		{name: "ExtractPath", fn: func() any { return ExtractPath(v, Path{"A"}) }},
+		{name: "Glob", fn: func() any {
+			var keys []string
+			Glob(v, "**", func(k string, _ any) { keys = append(keys, k) })
+			return keys
+		}, want: []string(nil)},
+		{name: "GlobKey", fn: func() any { return GlobKey(v, "**") }, want: []string(nil)},
+		{name: "GlobValues", fn: func() any { return GlobValues(v, "**") }, want: []any(nil)},
+		{name: "Compare", fn: func() any { return Compare(v, num(1)) }, want: -1},
+		{name: "Compare cycles", fn: func() any { return Compare(v, v) }, want: 0},
+		{name: "ObjectFieldFilter", fn: func() any { return ObjectFieldFilter{"A": {}}.Filter(v) }, want: false},
+		{name: "GlobFilter", fn: func() any { return GlobFilter{"A"}.Filter(v) }, want: false},
+		{name: "Delete", fn: func() any { return Delete(v, "A") }},
+		{name: "DeletePath", fn: func() any { return DeletePath(v, Path{"A"}) }},
+		{name: "DeleteCopy", fn: func() any { return DeleteCopy(v, "A") }},
+		{name: "Merge", fn: func() any { return Merge(v, num(2), "A", "") }},
+		{name: "MergeCopy", fn: func() any { return MergeCopy(v, num(2), "A", "") }},
+		{name: "MergePath", fn: func() any { return MergePath(v, num(2), Path{"A"}, nil) }},
+		{name: "Merge src", fn: func() any { return Merge(object{}, v, "a", "") }, want: object{}},
+		{name: "Sort", fn: func() any { return Sort(v) }},
+		{name: "SortCopy", fn: func() any { return SortCopy(v) }},
+		{name: "SortByKey", fn: func() any { return SortByKey(v, "A") }},
+		{name: "SortByKeyPath", fn: func() any { return SortByKeyPath(v, Path{"A"}) }},
+		{name: "SortByKeyCopy", fn: func() any { return SortByKeyCopy(v, "A") }},
+		{name: "Clone", fn: func() any { return Clone(v) }},
+		{name: "Freeze", fn: func() any { return Freeze(v) }},
+		{name: "Thaw", fn: func() any { return Thaw(v) }},
+		{name: "View", fn: func() any { return View(v).IsValid() }, want: false},
+		{name: "As", fn: func() any { _, ok := As[any](v); return ok }, want: false},
+		{name: "Query", fn: func() any { return jp.Query(v) }, want: []JSONPathNode(nil)},
+		{name: "Values", fn: func() any { return jp.Values(v) }, want: []any(nil)},
+		{name: "ObjectMapper", fn: func() any { return ObjectMapper{"A": AddScalar{num(1)}}.Map(v) }},
+		{name: "ArrayMapper", fn: func() any { return ArrayMapper{AddScalar{num(1)}}.Map(v) }},
+		{name: "CloneMapper", fn: func() any { return CloneMapper{ObjectMapper{}}.Map(v) }},
+		{name: "ArrayRemapper", fn: func() any { return ArrayRemapper{num(1)}.Map(v) }},
+		{name: "ObjectRemapper", fn: func() any { return ObjectRemapper{"A": num(1)}.Map(v) }},
+		{name: "ObjectReducer", fn: func() any { reducer.Add(v); return reducer.Value() }, want: object{"A": num(1)}},
+		{name: "ArrayReducer", fn: func() any {
+			r := ArrayReducer{&AnyReducer{V: num(1)}}
+			r.Add(v)
+			return r.Value()
+		}, want: array{num(1)}},
+		{name: "PartitionReducer", fn: func() any {
+			r := &PartitionReducer{New: func() Reducer { return &AnyReducer{} }, Key: "A"}
+			r.Add(v)
+			return r.Value()
+		}, want: array{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.fn()
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("%s(): got diff:\n%s", tc.name, diff)
+			}
+		})
+	}
+
+	for name, fn := range map[string]func() (any, error){
+		"Set":     func() (any, error) { return Set(v, "A", 2) },
+		"SetCopy": func() (any, error) { return SetCopy(v, "A", 2) },
+		"SetPath": func() (any, error) { return SetPath(v, Path{"A"}, 2) },
+		"Set x":   func() (any, error) { return Set(object{}, "a", v) },
+	} {
+		if _, err := fn(); !errors.Is(err, ErrCycle) {
+			t.Errorf("%s(): got err = %v, want ErrCycle", name, err)
+		}
+	}
+}
+
+type ptrValuer struct{ A int }
+
+func (v *ptrValuer) JSONGValue() any { return "valuer" }
+
+type ptrText struct{}
+
+func (*ptrText) MarshalText() ([]byte, error) { return []byte("text"), nil }
+
+// ptrValuerText converts differently when it is addressable.
+type ptrValuerText struct{ T ptrText }
+
+func (v *ptrValuerText) JSONGValue() any { return "valuer" }
+
+func TestValueOfPointerValuer(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{name: "pointer", input: &ptrValuer{}, want: str("valuer")},
+		{name: "value", input: ptrValuer{}, want: str("valuer")},
+		{name: "field", input: struct{ V ptrValuer }{}, want: object{"V": str("valuer")}},
+		{name: "addressable", input: &ptrValuerText{}, want: str("valuer")},
+		{name: "not addressable", input: ptrValuerText{}, want: object{"T": object{}}},
+	} {
+		got := ValueOf(tc.input)
+
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ValueOf(%s): got diff:\n%s", tc.name, diff)
+		}
+	}
+}
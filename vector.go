@@ -0,0 +1,123 @@
+package jsong
+
+// vectorNode is a node of a persistent vector trie. Leaves hold the
+// values and the other nodes hold child nodes. Nodes are never modified
+// after they are shared so updates copy the nodes along the path
+// of the index.
+type vectorNode struct {
+	children [vectorWidth]any
+}
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+// vector is a persistent vector of n values in a trie of nodes
+// with height shift/vectorBits+1.
+type vector struct {
+	root  *vectorNode
+	shift uint
+	n     int
+}
+
+func (v vector) get(i int) any {
+	n := v.root
+	for shift := v.shift; shift > 0; shift -= vectorBits {
+		n = n.children[(i>>shift)&vectorMask].(*vectorNode)
+	}
+	return n.children[i&vectorMask]
+}
+
+// with returns the vector with the value i set to x.
+// The index must be in range.
+func (v vector) with(i int, x any) vector {
+	v.root = v.root.with(v.shift, i, x)
+	return v
+}
+
+func (n *vectorNode) with(shift uint, i int, x any) *vectorNode {
+	res := new(vectorNode)
+	if n != nil {
+		*res = *n
+	}
+	if shift == 0 {
+		res.children[i&vectorMask] = x
+		return res
+	}
+	j := (i >> shift) & vectorMask
+	child, _ := res.children[j].(*vectorNode)
+	res.children[j] = child.with(shift-vectorBits, i, x)
+	return res
+}
+
+// push returns the vector with x appended.
+func (v vector) push(x any) vector {
+	if v.root == nil {
+		v.root = new(vectorNode)
+	}
+	if v.n == 1<<(v.shift+vectorBits) {
+		// Grow the trie by a level.
+		root := new(vectorNode)
+		root.children[0] = v.root
+		v.root, v.shift = root, v.shift+vectorBits
+	}
+	v.root = v.root.with(v.shift, v.n, x)
+	v.n++
+	return v
+}
+
+// each calls fn for the values of v in order until it returns false.
+func (v vector) each(fn func(i int, x any) bool) {
+	if v.n == 0 {
+		return
+	}
+	i := 0
+	v.root.each(v.shift, &i, v.n, fn)
+}
+
+func (n *vectorNode) each(shift uint, i *int, size int, fn func(i int, x any) bool) bool {
+	for _, c := range n.children {
+		if *i >= size {
+			return false
+		}
+		if shift == 0 {
+			if !fn(*i, c) {
+				return false
+			}
+			*i++
+			continue
+		}
+		if !c.(*vectorNode).each(shift-vectorBits, i, size, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// vectorOf returns a new vector of the values xs.
+func vectorOf(xs []any) vector {
+	if len(xs) == 0 {
+		return vector{}
+	}
+	// Build the leaves and then each level of the trie above them.
+	var nodes []*vectorNode
+	for i := 0; i < len(xs); i += vectorWidth {
+		n := new(vectorNode)
+		copy(n.children[:], xs[i:])
+		nodes = append(nodes, n)
+	}
+	var shift uint
+	for len(nodes) > 1 {
+		var parents []*vectorNode
+		for i, n := range nodes {
+			if i%vectorWidth == 0 {
+				parents = append(parents, new(vectorNode))
+			}
+			parents[len(parents)-1].children[i%vectorWidth] = n
+		}
+		nodes, shift = parents, shift+vectorBits
+	}
+	return vector{root: nodes[0], shift: shift, n: len(xs)}
+}
@@ -8,9 +8,13 @@ var (
 )
 
 func Visit(v any, visitFn func(k string, v any) error) error {
-	val, ok := v.(valueInterface)
+	x, err := ValueOfErr(v)
+	if err != nil {
+		return err
+	}
+	val, ok := x.(valueInterface)
 	if !ok {
-		val = ValueOf(v).(valueInterface)
+		val = null{}
 	}
 	if err := visit("", val, visitFn); err != nil && err != ErrStop {
 		return err